@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrAliasExists   = errors.New("alias already exists")
+	ErrAliasNotFound = errors.New("alias not found")
+)
+
+// ErrAliasCycle 别名之间的循环引用
+type ErrAliasCycle struct {
+	// 从新增的别名开始，末尾回到该别名
+	Chain []string
+}
+
+func (e *ErrAliasCycle) Error() string {
+	return "alias cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// Alias 为 bean 设置别名，可并发调用。别名已指向其它 bean 时返回 ErrAliasExists，构成循环时返回 ErrAliasCycle。
+// 子作用域中设置的别名不影响父容器
+func (c *Container) Alias(name, fullName string) error {
+	if name == "" || fullName == "" {
+		return fmt.Errorf("alias '%s' of bean '%s': name must not be empty", name, fullName)
+	}
+
+	c.aliasMu.Lock()
+	defer c.aliasMu.Unlock()
+	if n, ok := c.alias[name]; ok {
+		if n == fullName {
+			return nil
+		}
+		return fmt.Errorf("%w: '%s' -> '%s'", ErrAliasExists, name, n)
+	}
+
+	// 沿别名链查找，回到 name 即构成循环
+	chain := []string{name}
+	for n, ok := fullName, true; ok; n, ok = c.nextAlias(n) {
+		chain = append(chain, n)
+		if n == name {
+			return &ErrAliasCycle{Chain: chain}
+		}
+		if slices.Contains(chain[:len(chain)-1], n) {
+			break
+		}
+	}
+
+	c.alias[name] = fullName
+	return nil
+}
+
+// Unalias 移除别名，不影响父容器中的同名别名
+func (c *Container) Unalias(name string) error {
+	c.aliasMu.Lock()
+	defer c.aliasMu.Unlock()
+	if _, ok := c.alias[name]; !ok {
+		return fmt.Errorf("%w: '%s'", ErrAliasNotFound, name)
+	}
+	delete(c.alias, name)
+	return nil
+}
+
+// Aliases 获取所有别名及其指向的名称，包括父容器中未被覆盖的别名
+func (c *Container) Aliases() map[string]string {
+	aliases := make(map[string]string)
+	for container := c; container != nil; container = container.parent {
+		container.aliasMu.RLock()
+		for name, fullName := range container.alias {
+			if _, ok := aliases[name]; !ok {
+				aliases[name] = fullName
+			}
+		}
+		container.aliasMu.RUnlock()
+	}
+	return aliases
+}
+
+// 别名的下一级，调用方持有 aliasMu
+func (c *Container) nextAlias(name string) (string, bool) {
+	if n, ok := c.alias[name]; ok {
+		return n, true
+	}
+	if c.parent != nil {
+		return c.parent.aliasOf(name)
+	}
+	return "", false
+}
+
+// 解析别名链得到 bean 名称
+func (c *Container) resolve(name string) string {
+	if name == "" {
+		return name
+	}
+
+	// 父容器与子作用域分别设置的别名可能构成循环，解析到重复的名称时停止
+	var seen []string
+	for {
+		n, ok := c.aliasOf(name)
+		if !ok || slices.Contains(seen, n) {
+			return name
+		}
+		seen = append(seen, name)
+		name = n
+	}
+}
+
+// 子作用域沿用父容器的别名
+func (c *Container) aliasOf(name string) (string, bool) {
+	for container := c; container != nil; container = container.parent {
+		container.aliasMu.RLock()
+		n, ok := container.alias[name]
+		container.aliasMu.RUnlock()
+		if ok {
+			return n, true
+		}
+	}
+	return "", false
+}
+
+// 容器中的别名，按名称排序
+func (c *Container) aliasNames() []string {
+	c.aliasMu.RLock()
+	defer c.aliasMu.RUnlock()
+	return slices.Sorted(maps.Keys(c.alias))
+}
@@ -0,0 +1,243 @@
+package sdk
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/samber/do/v2"
+)
+
+// ErrBeanExists 注册的名称已存在，替换已注册的 bean 使用 OverrideBean
+var ErrBeanExists = errors.New("bean already exists")
+
+// bean 注册时记录的元信息
+type bean struct {
+	name      string
+	typ       reflect.Type
+	primary   bool
+	transient bool
+	eager     bool
+	// 集合注入中的顺序
+	order int
+	// 作用域 bean 所属的作用域名称
+	scope string
+	deps  []dependency
+	// 构造器读取的配置项
+	values []value
+	// 配置结构 bean 的绑定与校验
+	config func() error
+	// init 方法，在后置处理器之间执行
+	init func(any) error
+	// 创建实例的期限
+	timeout time.Duration
+	// 所属的模块
+	module string
+	// refresh 作用域，依赖被替换或读取的配置项变更时重新创建
+	refresh bool
+	// 重新注册 provider，do 关闭服务时会将其移除
+	rebuild func()
+	// 未经后置处理的 provider，Validate 预演时临时创建未创建的配置源
+	raw func() (any, error)
+	// 配置结构 bean 绑定的配置前缀
+	prefix string
+	// 注册位置，即注解构造器或 ProvideBean 的调用位置
+	location string
+}
+
+// bean 声明或运行时请求的依赖
+type dependency struct {
+	name     string
+	typ      reflect.Type
+	location string
+	// 通过 Provider/Lazy 延迟获取，不参与构造顺序与循环依赖检查
+	lazy bool
+	// 通过 Optional 获取，未注册时不视为错误
+	optional bool
+	// 通过 InvokeSlice/InvokeMap 获取所有可赋值给 typ 的 bean
+	all bool
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// 获取 bean 的元信息，不存在时创建
+func (c *Container) bean(name string) *bean {
+	b, ok := c.beans[name]
+	if !ok {
+		b = &bean{name: name}
+		c.beans[name] = b
+	}
+	return b
+}
+
+// 注册 bean 的元信息，名称已注册时返回 ErrBeanExists 且不修改任何元信息
+func (c *Container) register(name string, typ reflect.Type, transient bool, rebuild func(), raw func() (any, error)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, ok := c.beans[name]; ok && b.typ != nil {
+		return c.collide(name, b.module, b.location)
+	}
+	// 直接通过 Inject() 注册的服务
+	if c.provided(name) {
+		return c.collide(name, "", "")
+	}
+	c.define(name, typ, transient, rebuild)
+	c.beans[name].raw = raw
+	return nil
+}
+
+// 记录 bean 的元信息，覆盖已有的注册。调用方持有 mu
+func (c *Container) define(name string, typ reflect.Type, transient bool, rebuild func()) {
+	clear(c.index)
+	b := c.bean(name)
+	b.typ, b.transient, b.module = typ, transient, c.installingModule()
+	if location := callerLocation(); location != "" {
+		b.location = location
+	}
+	b.rebuild = rebuild
+}
+
+// Locate 声明 bean 的构造器位置，用于错误信息，由生成代码在注册前调用
+func (c *Container) Locate(name, location string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bean(name).location = location
+}
+
+// 声明的位置，生成代码中的声明以构造器位置为准
+func (b *bean) at(location string) string {
+	return elseOf(location == "", b.location, location)
+}
+
+// do 中是否已存在名为 name 的服务
+func (c *Container) provided(name string) bool {
+	return slices.ContainsFunc(c.inject.ListProvidedServices(), func(service do.EdgeService) bool {
+		return service.ScopeID == c.inject.ID() && service.Service == name
+	})
+}
+
+// 获取 bean 元信息的副本，可与注册并发调用
+func (c *Container) meta(name string) (b bean, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, exists := c.beans[name]; exists {
+		return *p, true
+	}
+	return
+}
+
+// bean 的注册位置
+func (c *Container) locationOf(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, ok := c.beans[name]; ok {
+		return b.location
+	}
+	return ""
+}
+
+// Primary 标记该 bean 为同类型中的首选，按类型获取存在多个候选时优先使用
+func (c *Container) Primary(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bean(c.resolve(name)).primary = true
+}
+
+// Eager 标记该 bean 为非懒加载，在 Run 时按依赖关系并发实例化
+func (c *Container) Eager(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bean(c.resolve(name)).eager = true
+}
+
+// Requires 声明 bean 的构造器依赖名为 dependency 的 T 类型 bean，由生成代码调用，不会实例化任何 bean
+func Requires[T any](container *Container, name, dependency string) {
+	container.depends(container.resolve(name), dependency, typeOf[T](), callerLocation(), false, false)
+}
+
+// RequiresLazy 声明通过 Provider/Lazy 获取的依赖
+func RequiresLazy[T any](container *Container, name, dependency string) {
+	container.depends(container.resolve(name), dependency, typeOf[T](), callerLocation(), true, false)
+}
+
+// RequiresOptional 声明通过 Optional 获取的依赖
+func RequiresOptional[T any](container *Container, name, dependency string) {
+	container.depends(container.resolve(name), dependency, typeOf[T](), callerLocation(), false, true)
+}
+
+func (c *Container) depends(name, dep string, typ reflect.Type, location string, lazy, optional bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.bean(name)
+	for _, d := range b.deps {
+		if d.name == dep {
+			return
+		}
+	}
+	b.deps = append(b.deps, dependency{dep, typ, location, lazy, optional, false})
+}
+
+// 按注册时声明的类型查找可赋值给 typ 的 bean 名称，结果按类型缓存，无需实例化任何 bean
+func (c *Container) assignable(typ reflect.Type) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if names, ok := c.index[typ]; ok {
+		return names
+	}
+
+	names := make([]string, 0)
+	for n, b := range c.beans {
+		if b.typ != nil && b.scope == "" && b.typ.AssignableTo(typ) {
+			names = append(names, n)
+		}
+	}
+
+	slices.Sort(names)
+	c.index[typ] = names
+	return names
+}
+
+// 查找唯一可赋值给 typ 的 bean 名称
+func (c *Container) lookup(typ reflect.Type) (name string, err error) {
+	candidates := c.assignable(typ)
+	var primaries []string
+	c.mu.Lock()
+	for _, n := range candidates {
+		if c.beans[n].primary {
+			primaries = append(primaries, n)
+		}
+	}
+	c.mu.Unlock()
+
+	switch {
+	case len(candidates) == 0:
+		err = &ErrBeanNotFound{Resolution: c.resolution("", typ)}
+	case len(candidates) == 1:
+		name = candidates[0]
+	case len(primaries) == 1:
+		name = primaries[0]
+	default:
+		candidates = slices.Clone(elseOf(len(primaries) > 1, primaries, candidates))
+		err = &ErrAmbiguous{Resolution: c.resolution("", typ), Candidates: candidates}
+	}
+	return
+}
+
+// InvokeAs 按类型获取 bean，无需关心生成器的命名规则。
+// 在已注册的 bean 中查找唯一可赋值给 T 的实例（接口或具体类型），存在多个时使用 Primary 标记的 bean
+func InvokeAs[T any](container *Container) (t T, err error) {
+	// 子作用域中不存在时回退到父容器
+	if container.parent != nil && len(container.assignable(typeOf[T]())) == 0 {
+		return InvokeAs[T](container.parent)
+	}
+
+	name, err := container.lookup(typeOf[T]())
+	if err != nil {
+		return
+	}
+	return invoke[T](container, name, true)
+}
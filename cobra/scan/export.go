@@ -8,11 +8,19 @@ import (
 
 // cobra 自动装配，需要定义一个名为rootCobra的cobra.ICobra实例
 
+// Module cobra 自动装配模块，可通过 container.Install(scan.Module) 安装
+var Module = &sdk.Module{Name: "cobra", Exports: []string{"cobraInitializer"}, Install: Injects}
+
 func Injects(container *sdk.Container) (_ error) {
-	sdk.ProvideBean[sdk.Initializer](container, "cobraInitializer", func() (i sdk.Initializer, err error) {
+	err := sdk.ProvideBean[sdk.Initializer](container, "cobraInitializer", func() (i sdk.Initializer, err error) {
 		i = CobraInitialized()
 		return
 	})
+	if err != nil {
+		return err
+	}
+	// 命令在容器初始化完成后执行
+	container.After("cobraInitializer", sdk.Initialized)
 	return
 }
 
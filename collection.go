@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Order 设置 bean 在集合注入中的顺序，值越小越靠前，相同时按名称排序
+func (c *Container) Order(name string, order int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bean(c.resolve(name)).order = order
+}
+
+// InvokeSlice 获取所有可赋值给 T 的 bean，按 Order 排序，仅实例化匹配的 bean。子作用域包括父容器中未被覆盖的 bean
+func InvokeSlice[T any](container *Container) (re []T, err error) {
+	names := container.visible(container.collect(typeOf[T]()))
+	re = make([]T, 0, len(names))
+	for _, name := range names {
+		t, iErr := invoke[T](container, name, true)
+		if iErr != nil {
+			err = errors.Join(err, iErr)
+			continue
+		}
+		re = append(re, t)
+	}
+	return
+}
+
+// InvokeMap 获取所有可赋值给 T 的 bean，以别名为键，没有别名时以 bean 名称为键
+func InvokeMap[T any](container *Container) (re map[string]T, err error) {
+	names := container.visible(container.collect(typeOf[T]()))
+	re = make(map[string]T, len(names))
+	for _, name := range names {
+		t, iErr := invoke[T](container, name, true)
+		if iErr != nil {
+			err = errors.Join(err, iErr)
+			continue
+		}
+		re[container.key(name)] = t
+	}
+	return
+}
+
+// RequiresAll 声明 bean 的构造器通过 InvokeSlice/InvokeMap 依赖所有可赋值给 T 的 bean，由生成代码调用
+func RequiresAll[T any](container *Container, name string) {
+	typ := typeOf[T]()
+	container.mu.Lock()
+	defer container.mu.Unlock()
+	b := container.bean(container.resolve(name))
+	if !slices.ContainsFunc(b.deps, func(d dependency) bool { return d.all && d.typ == typ }) {
+		b.deps = append(b.deps, dependency{name: "[]" + typ.String(), typ: typ, location: callerLocation(), all: true})
+	}
+}
+
+// bean 的依赖，集合依赖展开为可赋值给元素类型且对其可见的 bean。调用方持有 mu
+func (c *Container) dependencies(b *bean) []dependency {
+	deps := make([]dependency, 0, len(b.deps))
+	for _, dep := range b.deps {
+		if !dep.all {
+			deps = append(deps, dep)
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(c.beans)) {
+			d := c.beans[name]
+			if d.typ == nil || d.scope != "" || !d.typ.AssignableTo(dep.typ) {
+				continue
+			}
+			if d.module != "" && d.module != b.module && !c.exports(d.module, name) {
+				continue
+			}
+			deps = append(deps, dependency{name: name, typ: dep.typ, location: dep.location, lazy: dep.lazy})
+		}
+	}
+	return deps
+}
+
+// 查找容器及父容器中可赋值给 typ 的 bean，按 Order 与名称排序
+func (c *Container) collect(typ reflect.Type) (names []string) {
+	orders := make(map[string]int)
+	for container := c; container != nil; container = container.parent {
+		assignable := container.assignable(typ)
+		container.mu.Lock()
+		for _, name := range assignable {
+			if _, ok := orders[name]; !ok {
+				orders[name] = container.beans[name].order
+				names = append(names, name)
+			}
+		}
+		container.mu.Unlock()
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(orders[a], orders[b]), strings.Compare(a, b))
+	})
+	return
+}
+
+// 集合注入的键，优先使用排序后的第一个别名
+func (c *Container) key(name string) string {
+	var aliases []string
+	for container := c; container != nil; container = container.parent {
+		for _, alias := range container.aliasNames() {
+			if container.resolve(alias) == name {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+
+	if len(aliases) == 0 {
+		return name
+	}
+	return slices.Min(aliases)
+}
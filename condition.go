@@ -0,0 +1,172 @@
+package sdk
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Condition bean 的注册条件
+type Condition interface {
+	Matches(*Container) bool
+}
+
+// ConditionFunc 函数形式的注册条件
+type ConditionFunc func(*Container) bool
+
+// 依赖其它 bean 是否注册的条件，在其它条件注册完成后判断
+type missingBean string
+
+// Properties 配置源，env.Environment 满足该接口
+type Properties interface {
+	IsSet(key string) bool
+	Get(key string) any
+	GetString(key string) string
+	GetStringSlice(key string) []string
+}
+
+type conditional struct {
+	register   func() error
+	conditions []Condition
+	// 声明条件注册时正在安装的模块
+	module string
+}
+
+const profilesKey = "profiles.active"
+
+// Conditional 条件注册，在 Run 时依次判断所有条件，全部满足才执行 register
+func (c *Container) Conditional(register func(), conditions ...Condition) {
+	c.ConditionalErr(func() error { register(); return nil }, conditions...)
+}
+
+// ConditionalErr 同 Conditional，register 返回的错误（如别名冲突）由 Run 与 Validate 返回
+func (c *Container) ConditionalErr(register func() error, conditions ...Condition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conditionals = append(c.conditionals, conditional{register, conditions, c.installingModule()})
+}
+
+// 执行条件注册，包含 OnMissingBean 的条件注册最后判断
+func (c *Container) applyConditionals() (err error) {
+	c.mu.Lock()
+	conditionals := c.conditionals
+	c.conditionals = nil
+	c.mu.Unlock()
+	slices.SortStableFunc(conditionals, func(a, b conditional) int {
+		return elseOf(a.deferred() == b.deferred(), 0, elseOf(a.deferred(), 1, -1))
+	})
+
+	for _, cond := range conditionals {
+		ok := true
+		for _, condition := range cond.conditions {
+			if ok = condition.Matches(c); !ok {
+				break
+			}
+		}
+		if ok {
+			var rErr error
+			collided := c.within(cond.module, func() { rErr = cond.register() })
+			err = errors.Join(err, elseOf(rErr == nil, collided, rErr))
+		}
+	}
+	return
+}
+
+func (cond conditional) deferred() bool {
+	return slices.ContainsFunc(cond.conditions, func(condition Condition) bool {
+		_, ok := condition.(missingBean)
+		return ok
+	})
+}
+
+func (f ConditionFunc) Matches(c *Container) bool {
+	return f(c)
+}
+
+// SetProfiles 设置激活的 profile，覆盖配置中的 profiles.active
+func (c *Container) SetProfiles(profiles ...string) {
+	c.profiles = slices.Clone(profiles)
+	if c.profiles == nil {
+		c.profiles = make([]string, 0)
+	}
+}
+
+// Profiles 获取激活的 profile，未设置时读取配置中的 profiles.active
+func (c *Container) Profiles() []string {
+	if c.profiles != nil {
+		return slices.Clone(c.profiles)
+	}
+
+	properties := c.properties()
+	if properties == nil || !properties.IsSet(profilesKey) {
+		return nil
+	}
+
+	var profiles []string
+	for _, profile := range properties.GetStringSlice(profilesKey) {
+		for _, p := range strings.Split(profile, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				profiles = append(profiles, p)
+			}
+		}
+	}
+	return profiles
+}
+
+func (c *Container) properties() Properties {
+	if p := c.preview.Load(); p != nil {
+		return p.source(c)
+	}
+	properties, err := InvokeAs[Properties](c)
+	if err != nil {
+		return nil
+	}
+	return properties
+}
+
+// OnProfile 任一 profile 激活时满足，`!` 前缀表示该 profile 未激活时满足
+func OnProfile(profiles ...string) Condition {
+	return ConditionFunc(func(c *Container) bool {
+		active := c.Profiles()
+		for _, profile := range profiles {
+			if not := strings.HasPrefix(profile, "!"); not {
+				if !slices.Contains(active, profile[1:]) {
+					return true
+				}
+				continue
+			}
+			if slices.Contains(active, profile) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// OnProperty 配置项存在且有值时满足，`key=value` 形式要求配置值相等
+func OnProperty(property string) Condition {
+	key, value, matched := strings.Cut(property, "=")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	return ConditionFunc(func(c *Container) bool {
+		properties := c.properties()
+		if properties == nil || !properties.IsSet(key) {
+			return false
+		}
+		if matched {
+			return properties.GetString(key) == value
+		}
+		return properties.GetString(key) != "" || len(properties.GetStringSlice(key)) > 0
+	})
+}
+
+// OnMissingBean 指定的 bean 未注册时满足，用于提供默认实现
+func OnMissingBean(name string) Condition {
+	return missingBean(name)
+}
+
+func (name missingBean) Matches(c *Container) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.beans[c.resolve(string(name))]
+	return !ok || b.typ == nil
+}
@@ -0,0 +1,189 @@
+package sdk
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// mapstructure 错误信息中的字段名
+var fieldRegexp = regexp.MustCompile(`'([^']+)'`)
+
+// ProvideConfig 注册配置结构 bean，实例为 *T，由配置源中 prefix 下的配置按 mapstructure 标签填充，
+// 并按 validate 标签校验，支持 required、min、max、oneof，标记 omitempty 的字段未配置时只校验 required。无效的配置项在 Run 与 Validate 时一并报告
+func ProvideConfig[T any](container *Container, name, prefix string) error {
+	err := ProvideBean(container, name, func() (*T, error) {
+		t := new(T)
+		if err := container.bind(prefix, t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	container.mu.Lock()
+	defer container.mu.Unlock()
+	b := container.bean(container.resolve(name))
+	b.prefix = prefix
+	b.config = func() error {
+		return container.bind(prefix, new(T))
+	}
+	return nil
+}
+
+// 读取 prefix 下的配置填充 result 并校验
+func (c *Container) bind(prefix string, result any) error {
+	var raw any
+	if properties := c.properties(); properties != nil && properties.IsSet(prefix) {
+		raw = properties.Get(prefix)
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           result,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	invalid := &ErrInvalidConfig{Prefix: prefix}
+	if err = decoder.Decode(raw); err != nil {
+		var mErr *mapstructure.Error
+		if !errors.As(err, &mErr) {
+			mErr = &mapstructure.Error{Errors: []string{err.Error()}}
+		}
+		for _, reason := range mErr.Errors {
+			key := prefix
+			if match := fieldRegexp.FindStringSubmatch(reason); match != nil {
+				key = configKey(prefix, match[1])
+			}
+			invalid.add(key, reason)
+		}
+	}
+
+	validateConfig(reflect.ValueOf(result).Elem(), prefix, invalid)
+	if len(invalid.Keys) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// 按 validate 标签递归校验结构体字段，key 与 mapstructure 的字段名一致
+func validateConfig(v reflect.Value, prefix string, invalid *ErrInvalidConfig) {
+	typ := v.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		key := prefix
+		if !strings.Contains(opts, "squash") {
+			key = configKey(prefix, elseOf(name != "", name, field.Name))
+		}
+
+		fv := v.Field(i)
+		if rules := field.Tag.Get("validate"); rules != "" && !slices.Contains(invalid.Keys, key) {
+			list := strings.Split(rules, ",")
+			omitempty := strings.Contains(opts, "omitempty") || slices.ContainsFunc(list, func(rule string) bool { return strings.TrimSpace(rule) == "omitempty" })
+			for _, rule := range list {
+				if rule = strings.TrimSpace(rule); rule == "omitempty" {
+					continue
+				}
+				if reason := checkRule(fv, rule, omitempty); reason != "" {
+					invalid.add(key, reason)
+					break
+				}
+			}
+		}
+
+		if fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			validateConfig(fv, key, invalid)
+		}
+	}
+}
+
+// 校验单条规则，返回不满足的原因。标记 omitempty 的字段未配置（零值）时不校验 required 以外的规则
+func checkRule(v reflect.Value, rule string, omitempty bool) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	empty := v.IsZero() || (slices.Contains([]reflect.Kind{reflect.Slice, reflect.Map}, v.Kind()) && v.Len() == 0)
+	if name == "required" {
+		return elseOf(empty, "is required", "")
+	}
+	if empty && omitempty {
+		return ""
+	}
+	if v.Kind() == reflect.Pointer {
+		v = elseOf(v.IsNil(), reflect.Zero(v.Type().Elem()), v.Elem())
+	}
+
+	switch name {
+	case "min", "max":
+		var order int
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Sprintf("invalid rule '%s': %v", rule, err)
+			}
+			order = cmp.Compare(v.Len(), n)
+		default:
+			bound, err := convert(arg, v.Type())
+			if err != nil {
+				return fmt.Sprintf("invalid rule '%s': %v", rule, err)
+			}
+			order = compareValue(v, bound)
+		}
+		if name == "min" && order < 0 {
+			return fmt.Sprintf("must be >= %s", arg)
+		}
+		if name == "max" && order > 0 {
+			return fmt.Sprintf("must be <= %s", arg)
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		if !slices.Contains(options, fmt.Sprint(v.Interface())) {
+			return fmt.Sprintf("must be one of [%s]", strings.Join(options, ", "))
+		}
+	default:
+		return fmt.Sprintf("unsupported rule '%s'", rule)
+	}
+	return ""
+}
+
+func compareValue(a, b reflect.Value) int {
+	switch kind := a.Kind(); {
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	}
+	return 0
+}
+
+// 配置项的完整 key，与 viper 一致不区分大小写
+func configKey(prefix, name string) string {
+	return strings.ToLower(strings.TrimPrefix(prefix+"."+name, "."))
+}
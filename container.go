@@ -1,24 +1,37 @@
 package sdk
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/iocgo/sdk/proxy"
 	"github.com/iocgo/sdk/runtime"
 	"github.com/samber/do/v2"
+	"log/slog"
 	"os"
 	"os/signal"
+	"reflect"
 	run "runtime"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type keys struct {
 	sync.Mutex
-	g []string
+	// 所属的协程
+	id int64
+	g  []string
+	// 正在获取后置处理器
+	processing bool
+	// 当前解析的上下文
+	ctx context.Context
 }
 
+// Initializer 在 Run 时执行的初始化器，通过 DependsOn/Before/After 按 bean 名称声明先后关系，
+// Order 仅决定没有先后关系的初始化器之间的顺序
 type Initializer interface {
 	Init(*Container) error
 	Order() int
@@ -32,12 +45,54 @@ type singleInitializer struct {
 type Container struct {
 	inject *do.RootScope
 	alias  map[string]string
-	init   []func() error
+	// 保护 alias，解析别名时可能已持有 mu
+	aliasMu sync.RWMutex
+	beans   map[string]*bean
+	index   map[reflect.Type][]string
+	orders  map[string]*ordering
+	init    []func() error
+
+	profiles     []string
+	conditionals []conditional
+	decorators   []decorator
+
+	// 已安装的模块
+	modules    map[string]*Module
+	overrides  map[string]*Module
+	installed  []string
+	installing []string
+	// 安装模块期间的注册冲突
+	collisions []error
+
+	// 子作用域
+	parent *Container
+	scope  string
+	scoped map[string][]func(*Container)
+
+	parallelism int
+
+	mu       sync.Mutex
+	order    []string
+	destroys map[string]func(context.Context) error
+	timeout  time.Duration
+	stopped  atomic.Bool
+	// 关闭并重新注册 refresh bean 期间阻塞获取
+	refreshing sync.RWMutex
+	// refresh 的次数，间接实现据此判断缓存的实例是否仍为当前实例
+	generation atomic.Uint64
+	// Validate 预演条件注册期间的状态
+	preview atomic.Pointer[preview]
+
+	// 容器的上下文，Stop 或 Run 收到信号时取消
+	ctx     context.Context
+	cancel  context.CancelFunc
+	logger  *slog.Logger
+	metrics *metrics
 }
 
 var (
 	threadLocal = runtime.NewThreadLocal[*keys](func() *keys {
-		return &keys{}
+		return &keys{id: runtime.GetCurrentGoroutineID()}
 	})
 )
 
@@ -53,6 +108,65 @@ func (k *keys) push(key string) (re bool) {
 	return
 }
 
+func (k *keys) pop() {
+	k.Lock()
+	defer k.Unlock()
+
+	if l := len(k.g); l > 0 {
+		k.g = k.g[:l-1]
+	}
+}
+
+func (k *keys) snapshot() []string {
+	k.Lock()
+	defer k.Unlock()
+	return slices.Clone(k.g)
+}
+
+// 当前正在创建的 bean，直接获取或获取后置处理器时为空
+func (k *keys) requester() string {
+	k.Lock()
+	defer k.Unlock()
+	if k.processing || len(k.g) == 0 {
+		return ""
+	}
+	return k.g[len(k.g)-1]
+}
+
+// 设置是否正在获取后置处理器，返回是否发生了变化
+func (k *keys) process(processing bool) bool {
+	k.Lock()
+	defer k.Unlock()
+	if k.processing == processing {
+		return false
+	}
+	k.processing = processing
+	return true
+}
+
+func (k *keys) context() context.Context {
+	k.Lock()
+	defer k.Unlock()
+	return k.ctx
+}
+
+// 替换当前解析的上下文，返回原上下文
+func (k *keys) swap(ctx context.Context) (prev context.Context) {
+	k.Lock()
+	defer k.Unlock()
+	prev, k.ctx = k.ctx, ctx
+	return
+}
+
+// 进入当前协程的依赖解析，返回其状态与退出方法。
+// 协程不继承创建它的协程的依赖链，跨协程的循环依赖在请求其它协程正在创建的 bean 时检查
+func enter() (value *keys, leave func()) {
+	if threadLocal.Ex(true) {
+		return threadLocal.Load(), func() {}
+	}
+	return threadLocal.Load(), threadLocal.Remove
+}
+
 func (i singleInitializer) Init(container *Container) (err error) {
 	if i.init == nil {
 		return
@@ -65,10 +179,39 @@ func (i singleInitializer) Order() int {
 }
 
 func NewContainer() *Container {
-	return &Container{
+	return newContainer(context.Background())
+}
+
+// 创建容器，其上下文派生自 parent，子作用域的上下文随父容器取消
+func newContainer(parent context.Context) *Container {
+	c := &Container{
 		inject: do.New(),
 		alias:  make(map[string]string),
+		beans:  make(map[string]*bean),
+		index:  make(map[reflect.Type][]string),
+		orders: make(map[string]*ordering),
+		scoped: make(map[string][]func(*Container)),
+
+		modules:   make(map[string]*Module),
+		overrides: make(map[string]*Module),
+
+		timeout:  defaultShutdownTimeout,
+		destroys: make(map[string]func(context.Context) error),
+		logger:   discardLogger,
+		metrics:  &metrics{beans: make(map[string]*beanStats)},
 	}
+	c.ctx, c.cancel = context.WithCancel(parent)
+
+	// 直接通过 do 关闭的 bean 同样执行其销毁方法
+	c.inject.AddBeforeShutdownHook(func(scope *do.Scope, name string) {
+		if scope.ID() != c.inject.ID() {
+			return
+		}
+		if err := c.destroy(context.Background(), name); err != nil {
+			c.logger.Error("destroy bean failed", "bean", name, "error", err)
+		}
+	})
+	return c
 }
 
 func InitializedWrapper(order int, init func(*Container) error) Initializer {
@@ -80,48 +223,100 @@ func (c *Container) AddInitialized(i func() error) {
 }
 
 func (c *Container) Run(signals ...os.Signal) (err error) {
-	beans := ListInvokeAs[Initializer](c)
-	beans = append(beans, &singleInitializer{999, func(container *Container) (iErr error) {
-		for _, exec := range c.init {
-			if iErr = exec(); iErr != nil {
-				return iErr
-			}
-		}
+	if err = c.applyConditionals(); err != nil {
 		return
-	}})
+	}
+
+	if err = c.checkExports(); err != nil {
+		return
+	}
 
-	if len(beans) > 0 {
-		slices.SortFunc[[]Initializer](beans, func(a, b Initializer) int {
-			return elseOf(a.Order() == b.Order(), 0, elseOf(a.Order() > b.Order(), 1, -1))
-		})
+	// 配置项错误在启动时报告，不必等到懒加载的 bean 实例化
+	if err = c.checkValues(); err != nil {
+		return
+	}
 
-		for _, bean := range beans {
-			if err = bean.Init(c); err != nil {
-				return
+	// 启动期间收到信号时取消容器的上下文，正在创建的 bean 随之中止
+	var (
+		w           chan os.Signal
+		interrupted = make(chan os.Signal, 1)
+		started     = make(chan struct{})
+		// 启动失败时同样结束监听信号的协程
+		stopWatching = sync.OnceFunc(func() { close(started) })
+	)
+	defer stopWatching()
+	if len(signals) > 0 {
+		w = make(chan os.Signal, 1)
+		signal.Notify(w, signals...)
+		defer signal.Stop(w)
+		go func() {
+			select {
+			case sig := <-w:
+				interrupted <- sig
+				c.cancel()
+			case <-started:
 			}
-		}
+		}()
 	}
 
-	// Logger
+	now := time.Now()
+	if err = c.start(); err != nil {
+		c.logger.Error("container start failed", "duration", time.Since(now), "error", err)
+		select {
+		case sig := <-interrupted:
+			err = errors.Join(err, c.shutdown(sig))
+		default:
+		}
+		return
+	}
+	stopWatching()
+	c.mu.Lock()
+	created := len(c.order)
+	c.mu.Unlock()
+	c.logger.Info("container started", "created", created, "duration", time.Since(now))
 
-	// TODO -
 	if len(signals) > 0 {
-		w := make(chan os.Signal, 1)
-		signal.Notify(w, signals...)
-		<-w
+		var sig os.Signal
+		select {
+		case sig = <-interrupted:
+		case sig = <-w:
+		}
+		err = c.shutdown(sig)
 	}
 	return
 }
 
-func (c *Container) Inject() *do.RootScope {
-	return c.inject
-}
+// 按顺序执行初始化器
+func (c *Container) start() error {
+	inits, err := c.initializers()
+	if err != nil {
+		return err
+	}
 
-func (c *Container) Alias(name, fullName string) {
-	if n, ok := c.alias[name]; ok {
-		panic("alias '" + n + "' already exists")
+	if inits, err = c.sortInitializers(inits); err != nil {
+		return err
+	}
+	names := make([]string, len(inits))
+	for idx, i := range inits {
+		names[idx] = i.name
+	}
+	c.logger.Info("initializers sorted", "order", names)
+
+	for _, i := range inits {
+		if err = c.dependsOn(i.name); err != nil {
+			return err
+		}
+		now := time.Now()
+		if err = i.Init(c); err != nil {
+			return err
+		}
+		c.logger.Debug("initializer done", "initializer", i.name, "duration", time.Since(now))
 	}
-	c.alias[name] = fullName
+	return nil
+}
+
+func (c *Container) Inject() *do.RootScope {
+	return c.inject
 }
 
 func (c *Container) HealthLogger() string {
@@ -129,115 +324,214 @@ func (c *Container) HealthLogger() string {
 	return injector.String()
 }
 
-func (c *Container) Stop() (err error) {
-	err = c.inject.Shutdown()
-	if err != nil {
-		return
-	}
-
-	// TODO
-	return
-}
-
 func NameOf[T any]() string {
 	return do.NameOf[T]()
 }
 
-func ProvideBean[T any](container *Container, name string, provider func() (T, error)) {
-	do.ProvideNamed[T](container.inject, name, func(i do.Injector) (T, error) {
-		return provider()
-	})
+// ProvideBean 注册单例 bean，名称已注册时返回 ErrBeanExists
+func ProvideBean[T any](container *Container, name string, provider func() (T, error)) error {
+	rebuild := func() {
+		do.OverrideNamed[T](container.inject, name, provide(container, name, provider))
+	}
+	// Validate 预演条件注册时只记录元信息
+	if err := container.register(name, typeOf[T](), false, rebuild, erase(provider)); err != nil || container.previewing() {
+		return err
+	}
+	do.ProvideNamed[T](container.inject, name, provide(container, name, provider))
+	return nil
 }
 
-func ProvideTransient[T any](container *Container, name string, provider func() (T, error)) {
-	do.ProvideNamedTransient[T](container.inject, name, func(i do.Injector) (T, error) {
-		return provider()
-	})
+// ProvideTransient 注册每次获取都重新创建的 bean，名称已注册时返回 ErrBeanExists
+func ProvideTransient[T any](container *Container, name string, provider func() (T, error)) error {
+	if err := container.register(name, typeOf[T](), true, nil, erase(provider)); err != nil || container.previewing() {
+		return err
+	}
+	do.ProvideNamedTransient[T](container.inject, name, provide(container, name, provider))
+	return nil
 }
 
-func OverrideBean[T any](container *Container, name string, provider func() (T, error)) {
-	do.OverrideNamed[T](container.inject, name, func(i do.Injector) (T, error) {
-		return provider()
+// OverrideBean 替换 bean 的 provider，已创建的旧实例随即关闭，依赖它的 refresh bean 重新创建。
+// 旧实例仍被不会重新创建的 bean 持有时替换同样生效，并返回 ErrBeanHeld
+func OverrideBean[T any](container *Container, name string, provider func() (T, error)) error {
+	rebuild := func() {
+		do.OverrideNamed[T](container.inject, name, provide(container, name, provider))
+	}
+	return container.refresh([]string{name}, func() {
+		container.mu.Lock()
+		container.define(name, typeOf[T](), false, rebuild)
+		container.mu.Unlock()
+		rebuild()
 	})
 }
 
+// 擦除 provider 的返回类型
+func erase[T any](provider func() (T, error)) func() (any, error) {
+	return func() (any, error) { return provider() }
+}
+
 func InvokeBean[T any](container *Container, name string) (t T, err error) {
-	if name != "" {
-		for {
-			if n, ok := container.alias[name]; ok {
-				name = n
-			} else {
-				break
-			}
+	key := container.resolve(name)
+	if key != name {
+		container.logger.Debug("alias resolved", "alias", name, "bean", key)
+	}
+	return invoke[T](container, key, false)
+}
+
+// assignable: 已确认该 bean 可赋值给 T，按 any 获取后断言（do 无法按接口获取 transient bean）
+func invoke[T any](container *Container, name string, assignable bool) (t T, err error) {
+	if container.stopped.Load() {
+		err = ErrStopped
+		return
+	}
+
+	// 子作用域中不存在时回退到父容器
+	if container.parent != nil && !container.provides(elseOf(name == "", NameOf[T](), name)) {
+		return invoke[T](container.parent, name, assignable)
+	}
+
+	value, leave := enter()
+	defer leave()
+
+	key := elseOf(name == "", NameOf[T](), name)
+	requester := value.requester()
+	if !value.push(key) {
+		chain := value.snapshot()
+		value.pop()
+		err = &ErrCycle{Name: key, Chain: chain, Locations: container.located(chain, value.locations(len(chain)))}
+		return
+	}
+	defer value.pop()
+
+	// checked，等待正在进行的 refresh 完成关闭与重新注册，构造器执行期间不持有该锁
+	container.refreshing.RLock()
+	b, ok := container.meta(key)
+	registered := name == "" || container.registered(key)
+	container.refreshing.RUnlock()
+	switch {
+	case ok && b.scope != "":
+		err = &ErrBeanNotFound{Resolution: container.resolution(key, typeOf[T]()), Scope: b.scope}
+		return
+	case !registered:
+		err = &ErrBeanNotFound{Resolution: container.resolution(key, typeOf[T]())}
+		return
+	case ok && b.typ != nil && !b.typ.AssignableTo(typeOf[T]()):
+		err = fmt.Errorf("%w: bean '%s' of type %s is not assignable to %s", do.ErrServiceNotMatch, key, b.typ, typeOf[T]())
+		return
+	}
+	// 其它协程正在创建该 bean，当前协程由其在创建期间派生时将永远等待
+	if ok && !b.transient {
+		if cycle := container.crossCycle(value, key); cycle != nil {
+			err = cycle
+			return
+		}
+	}
+	// 模块外的 bean 只能依赖公开的 bean
+	if err = container.exported(requester, key); err != nil {
+		return
+	}
+
+	// 记录运行时的依赖关系
+	if chain := value.snapshot(); len(chain) > 1 {
+		if _, ok := container.meta(chain[len(chain)-2]); ok {
+			container.depends(chain[len(chain)-2], key, typeOf[T](), "", false, false)
 		}
 	}
 
-	// var zero T
-	// if !threadLocal.Ex(true) {
-	// 	defer threadLocal.Remove()
-	// }
-	//
-	// // checked
-	// value := threadLocal.Load()
-	// if !value.push(name) {
-	// 	// TODO - 未处理多例的情况
-	// 	return zero, warpError(fmt.Errorf("acircular dependency occurs:\n%s", join(value.g, name)))
-	// }
+	// do 按指针的元素类型判断是否实现接口，已注册的类型可赋值给 T 时同样按 any 获取后断言
+	if ok && b.typ != nil && b.typ != typeOf[T]() {
+		assignable = true
+	}
 
-	if name == "" {
+	switch {
+	case name == "":
 		t, err = do.Invoke[T](container.inject)
-	} else {
+	case assignable:
+		var obj any
+		if obj, err = do.InvokeNamed[any](container.inject, name); err == nil {
+			t, err = AssertToError[T](obj)
+		}
+	default:
 		t, err = do.InvokeNamed[T](container.inject, name)
 	}
 
 	if err != nil {
+		err = container.warpError(key, typeOf[T](), err)
 		return
 	}
+	container.created(key)
 
 	// proxy
 	if px, pxErr := proxy.New[T](t); pxErr == nil {
+		container.logger.Debug("proxy wrapped", "bean", key, "type", typeOf[T]())
 		t = px
 	}
+
+	// refresh bean 以接口类型获取时返回间接实现
+	if ok && b.refresh {
+		t = indirect(container, key, t)
+	}
 	return
 }
 
+// ListInvokeAs 获取所有声明类型可赋值给 T 的 bean，忽略实例化失败的 bean
 func ListInvokeAs[T any](container *Container) (re []T) {
-	services := container.inject.ListProvidedServices()
-	for _, ser := range services {
-		t, err := InvokeBean[T](container, ser.Service)
-		if err == nil {
-			re = append(re, t)
-		}
-	}
+	re, _ = InvokeSlice[T](container)
 	return
 }
 
-func warpError(err error) error {
-	if err != nil {
-		count := -1
-		frame := runtime.CallerFrame(func(fe run.Frame) (ok bool) {
-			if count > -1 {
-				count++
-				return count >= 2 // 第x个栈
-			}
+// 包装构造器返回的错误：转换为带有构造器位置（由 Locate 声明或注册时的调用位置）的 ErrProviderFailed，
+// 依赖链上已转换的错误保持不变
+func (c *Container) warpError(name string, typ reflect.Type, err error) error {
+	var (
+		notFound  *ErrBeanNotFound
+		ambiguous *ErrAmbiguous
+		cycle     *ErrCycle
+		provider  *ErrProviderFailed
+	)
+	if errors.As(err, &notFound) || errors.As(err, &ambiguous) || errors.As(err, &cycle) || errors.As(err, &provider) {
+		return err
+	}
+	return &ErrProviderFailed{Resolution: c.resolution(name, typ), Location: c.locationOf(name), Err: err}
+}
 
-			if i := len(fe.File); fe.File[i-12:] == "container.go" &&
-				fe.Function == "github.com/iocgo/sdk.warpError" {
-				count = 0 // 开始计数
-			}
-			return
-		})
+// 当前协程的解析路径
+func (c *Container) resolution(name string, typ reflect.Type) (r Resolution) {
+	r = Resolution{Name: name, Type: typ.String()}
+	if threadLocal.Ex(false) {
+		value := threadLocal.Load()
+		r.Path = value.snapshot()
+		r.Locations = c.located(r.Path, value.locations(len(r.Path)))
+	}
+	return
+}
 
-		if frame != nil {
-			tr := strings.Split(frame.Function, ".")
-			err = errors.Join(err, fmt.Errorf(`in %s # %s:%d`, frame.File, tr[:len(tr)-1], frame.Line))
+// 定位 sdk 包外最近的调用栈，即发起调用的注解构造器
+func callerFrame() *run.Frame {
+	inside := false
+	return runtime.CallerFrame(func(fe run.Frame) bool {
+		if strings.HasPrefix(fe.Function, "github.com/iocgo/sdk.") ||
+			strings.HasPrefix(fe.Function, "github.com/iocgo/sdk/runtime.") {
+			inside = true
+			return false
 		}
+		return inside
+	})
+}
+
+// 生成代码中的调用不记录位置，由 Locate 声明的构造器位置代替
+func callerLocation() string {
+	if frame := callerFrame(); frame != nil && !generated(frame.File) {
+		return fmt.Sprintf("%s:%d", frame.File, frame.Line)
 	}
+	return ""
+}
 
-	return err
+// 是否为 iocgo 生成的代码文件
+func generated(file string) bool {
+	return strings.HasSuffix(file, ".gen.go")
 }
 
-func join(slice []string, n string) (str string) {
+func join(slice []string, n string, locations []string) (str string) {
 	idx := -1
 	sliceL := len(slice)
 	for i, it := range slice {
@@ -245,6 +539,11 @@ func join(slice []string, n string) (str string) {
 			idx = i
 		}
 
+		// 第i个bean的构造器位置即其请求第i+1个bean的位置
+		if i+1 < len(locations) && locations[i+1] != "" {
+			it += "  in " + locations[i+1]
+		}
+
 		switch i {
 		case idx:
 			str += "╭- " + it + "\n"
@@ -0,0 +1,1289 @@
+package sdk_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iocgo/sdk"
+	"github.com/iocgo/sdk/proxy"
+	"github.com/samber/do/v2"
+)
+
+type (
+	beanA struct{ b *beanB }
+	beanB struct{ a *beanA }
+)
+
+func TestInvokeBeanCycle(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		b, err := sdk.InvokeBean[*beanB](container, "b")
+		return &beanA{b}, err
+	})
+	sdk.ProvideTransient[*beanB](container, "b", func() (*beanB, error) {
+		a, err := sdk.InvokeBean[*beanA](container, "a")
+		return &beanB{a}, err
+	})
+
+	_, err := sdk.InvokeBean[*beanA](container, "a")
+	require.Error(t, err)
+
+	var cycle *sdk.ErrCycle
+	require.True(t, errors.As(err, &cycle))
+	assert.Equal(t, "a", cycle.Name)
+	assert.Equal(t, []string{"a", "b", "a"}, cycle.Chain)
+	assert.Contains(t, err.Error(), "╭- a")
+	assert.Contains(t, err.Error(), "╰> a")
+}
+
+func TestInvokeBeanCycleAcrossGoroutines(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		ch := make(chan error)
+		go func() {
+			_, err := sdk.InvokeBean[*beanB](container, "b")
+			ch <- err
+		}()
+		return &beanA{}, <-ch
+	})
+	sdk.ProvideBean[*beanB](container, "b", func() (*beanB, error) {
+		a, err := sdk.InvokeBean[*beanA](container, "a")
+		return &beanB{a}, err
+	})
+
+	_, err := sdk.InvokeBean[*beanA](container, "a")
+	var cycle *sdk.ErrCycle
+	require.True(t, errors.As(err, &cycle))
+	assert.Equal(t, []string{"a", "b", "a"}, cycle.Chain)
+	assert.Contains(t, cycle.Locations[2], "container_test.go")
+
+	// 间接派生的协程在等待超时后解析所有协程的调用栈
+	container = sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		ch := make(chan error)
+		go func() {
+			inner := make(chan error)
+			go func() {
+				_, err := sdk.InvokeBean[*beanA](container, "a")
+				inner <- err
+			}()
+			ch <- <-inner
+		}()
+		return &beanA{}, <-ch
+	})
+	_, err = sdk.InvokeBean[*beanA](container, "a")
+	require.True(t, errors.As(err, &cycle))
+	assert.Equal(t, []string{"a", "a"}, cycle.Chain)
+}
+
+func TestInvokeBeanAcrossGoroutinesNoCycle(t *testing.T) {
+	container := sdk.NewContainer()
+	started, requested := make(chan struct{}), make(chan struct{})
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		close(started)
+		<-requested
+		time.Sleep(50 * time.Millisecond)
+		return &beanA{}, nil
+	})
+
+	// 创建 a 之前派生的协程等待 a 创建完成，而不是循环依赖
+	done := make(chan error)
+	go func() {
+		<-started
+		close(requested)
+		_, err := sdk.InvokeBean[*beanA](container, "a")
+		done <- err
+	}()
+
+	_, err := sdk.InvokeBean[*beanA](container, "a")
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+}
+
+func TestInvokeBeanNoCycle(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideTransient[*beanB](container, "b", func() (*beanB, error) {
+		return &beanB{}, nil
+	})
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		_, err := sdk.InvokeBean[*beanB](container, "b")
+		if err != nil {
+			return nil, err
+		}
+		b, err := sdk.InvokeBean[*beanB](container, "b")
+		return &beanA{b}, err
+	})
+
+	a, err := sdk.InvokeBean[*beanA](container, "a")
+	require.NoError(t, err)
+	assert.NotNil(t, a.b)
+}
+
+type echo interface{ Echo() string }
+
+type (
+	echoA struct{}
+	echoB struct{}
+)
+
+func (echoA) Echo() string { return "A" }
+func (echoB) Echo() string { return "B" }
+
+func TestInvokeAs(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*echoA](container, "a", func() (*echoA, error) { return &echoA{}, nil })
+
+	e, err := sdk.InvokeAs[echo](container)
+	require.NoError(t, err)
+	assert.Equal(t, "A", e.Echo())
+
+	a, err := sdk.InvokeAs[*echoA](container)
+	require.NoError(t, err)
+	assert.NotNil(t, a)
+
+	sdk.ProvideTransient[*echoB](container, "b", func() (*echoB, error) { return &echoB{}, nil })
+	_, err = sdk.InvokeAs[echo](container)
+	var ambiguous *sdk.ErrAmbiguous
+	require.True(t, errors.As(err, &ambiguous))
+	assert.Equal(t, []string{"a", "b"}, ambiguous.Candidates)
+
+	container.Primary("b")
+	e, err = sdk.InvokeAs[echo](container)
+	require.NoError(t, err)
+	assert.Equal(t, "B", e.Echo())
+
+	_, err = sdk.InvokeAs[error](container)
+	assert.Error(t, err)
+}
+
+func TestListInvokeAsLazy(t *testing.T) {
+	container := sdk.NewContainer()
+	built := false
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		built = true
+		return &beanA{}, nil
+	})
+	sdk.ProvideBean[*echoA](container, "echo.a", func() (*echoA, error) { return &echoA{}, nil })
+	sdk.ProvideTransient[echo](container, "echo.b", func() (echo, error) { return &echoB{}, nil })
+
+	beans := sdk.ListInvokeAs[echo](container)
+	require.Len(t, beans, 2)
+	assert.Equal(t, "A", beans[0].Echo())
+	assert.Equal(t, "B", beans[1].Echo())
+
+	require.NoError(t, container.Run())
+	assert.False(t, built)
+}
+
+type closer struct {
+	name   string
+	closed *[]string
+}
+
+func (c *closer) Shutdown() error {
+	*c.closed = append(*c.closed, c.name)
+	return nil
+}
+
+func TestStopOrder(t *testing.T) {
+	var closed []string
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*closer](container, "db", func() (*closer, error) {
+		return &closer{"db", &closed}, nil
+	})
+	sdk.ProvideBean[*closer](container, "repo", func() (*closer, error) {
+		if _, err := sdk.InvokeBean[*closer](container, "db"); err != nil {
+			return nil, err
+		}
+		return &closer{"repo", &closed}, nil
+	})
+	sdk.ProvideBean[*closer](container, "unused", func() (*closer, error) {
+		return &closer{"unused", &closed}, nil
+	})
+
+	_, err := sdk.InvokeBean[*closer](container, "repo")
+	require.NoError(t, err)
+
+	require.NoError(t, container.Stop())
+	assert.Equal(t, []string{"repo", "db"}, closed)
+
+	_, err = sdk.InvokeBean[*closer](container, "repo")
+	assert.ErrorIs(t, err, sdk.ErrStopped)
+	assert.NoError(t, container.Stop())
+}
+
+func TestRunShutdownOnSignal(t *testing.T) {
+	var closed []string
+	container := sdk.NewContainer()
+	container.ShutdownTimeout(time.Second)
+	sdk.ProvideBean[*closer](container, "db", func() (*closer, error) {
+		return &closer{"db", &closed}, nil
+	})
+	container.AddInitialized(func() (err error) {
+		_, err = sdk.InvokeBean[*closer](container, "db")
+		return
+	})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	}()
+	require.NoError(t, container.Run(syscall.SIGUSR1))
+	assert.Equal(t, []string{"db"}, closed)
+}
+
+func TestDestroyHook(t *testing.T) {
+	var closed []string
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*closer](container, "db", func() (*closer, error) {
+		c := &closer{"db", &closed}
+		container.OnDestroy("db", sdk.Hook(func() { closed = append(closed, "db.Close") }))
+		return c, nil
+	})
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		if err := sdk.Hook(func() error { return errors.New("init failed") })(context.Background()); err != nil {
+			return nil, err
+		}
+		return &beanA{}, nil
+	})
+
+	_, err := sdk.InvokeBean[*beanA](container, "a")
+	assert.ErrorContains(t, err, "init failed")
+
+	// 销毁方法收到关闭容器的上下文
+	type key struct{}
+	sdk.ProvideBean[*closer](container, "cache", func() (*closer, error) {
+		container.OnDestroy("cache", sdk.Hook(func(ctx context.Context) error {
+			closed = append(closed, ctx.Value(key{}).(string))
+			return nil
+		}))
+		return &closer{"cache", &closed}, nil
+	})
+
+	_, err = sdk.InvokeBean[*closer](container, "db")
+	require.NoError(t, err)
+	_, err = sdk.InvokeBean[*closer](container, "cache")
+	require.NoError(t, err)
+	require.NoError(t, container.StopWithContext(context.WithValue(context.Background(), key{}, "stop")))
+	assert.Equal(t, []string{"stop", "cache", "db.Close", "db"}, closed)
+}
+
+func TestGraph(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+	sdk.ProvideTransient[*beanB](container, "b", func() (*beanB, error) { return &beanB{}, nil })
+	sdk.Requires[*beanA](container, "b", "alias.a")
+	sdk.Requires[*echoA](container, "b", "missing")
+	container.Alias("alias.a", "a")
+	container.Eager("a")
+
+	g := container.Graph()
+	require.Len(t, g.Nodes, 3)
+	assert.Equal(t, sdk.GraphNode{Name: "a", Type: "*sdk_test.beanA", Aliases: []string{"alias.a"}, Eager: true}, g.Nodes[0])
+	assert.True(t, g.Nodes[1].Transient)
+	assert.True(t, g.Nodes[2].Missing)
+	assert.Equal(t, []sdk.GraphEdge{{"b", "a"}, {"b", "missing"}}, g.Edges)
+
+	assert.Contains(t, g.DOT(), `"b" -> "a";`)
+	assert.Contains(t, g.Mermaid(), "n1 --> n0")
+	data, err := g.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"aliases": [`)
+}
+
+func TestConditional(t *testing.T) {
+	container := sdk.NewContainer()
+	container.SetProfiles("prod")
+	provide := func(name string) func() {
+		return func() {
+			sdk.ProvideBean[*echoA](container, name, func() (*echoA, error) { return &echoA{}, nil })
+		}
+	}
+
+	// OnMissingBean 在其它条件注册之后判断
+	container.Conditional(provide("mem"), sdk.OnMissingBean("redis"))
+	container.Conditional(provide("redis"), sdk.OnProfile("prod"))
+	container.Conditional(provide("dev"), sdk.OnProfile("dev"))
+	container.Conditional(provide("test"), sdk.OnProfile("!test"))
+	container.Conditional(provide("flag"), sdk.OnProperty("cache.enabled"))
+	require.NoError(t, container.Run())
+
+	names := make([]string, 0)
+	for _, node := range container.Graph().Nodes {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"redis", "test"}, names)
+}
+
+func TestValidate(t *testing.T) {
+	container := sdk.NewContainer()
+	constructed := false
+	provide := func() (*beanA, error) { constructed = true; return &beanA{}, nil }
+	sdk.ProvideBean[*beanA](container, "a", provide)
+	sdk.ProvideBean[*beanA](container, "c", provide)
+	sdk.ProvideTransient[*beanB](container, "b", func() (*beanB, error) { return &beanB{}, nil })
+	sdk.Requires[*beanB](container, "a", "b")
+	sdk.Requires[*beanA](container, "b", "a")
+	sdk.Requires[*echoA](container, "c", "missing")
+	sdk.Requires[*beanB](container, "c", "a")
+
+	err := container.Validate()
+	require.Error(t, err)
+	assert.False(t, constructed)
+
+	var cycle *sdk.ErrCycle
+	require.True(t, errors.As(err, &cycle))
+	assert.Equal(t, []string{"a", "b", "a"}, cycle.Chain)
+	assert.Contains(t, err.Error(), "bean 'c' requires 'missing'")
+	assert.Contains(t, err.Error(), "required *sdk_test.beanB, but provided *sdk_test.beanA")
+	assert.Contains(t, err.Error(), "container_test.go:")
+
+	container = sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", provide)
+	sdk.Requires[*beanA](container, "b", "a")
+	assert.NoError(t, container.Validate())
+}
+
+func TestValidateWithoutSideEffects(t *testing.T) {
+	container := sdk.NewContainer()
+	var sources, constructed int
+	sdk.ProvideBean[properties](container, "properties", func() (properties, error) {
+		sources++
+		return properties{"cache.enabled": "true"}, nil
+	})
+	container.ConditionalErr(func() error {
+		if err := sdk.ProvideTransient[*echoA](container, "flag", func() (*echoA, error) { constructed++; return &echoA{}, nil }); err != nil {
+			return err
+		}
+		sdk.Requires[*echoA](container, "flag", "missing")
+		return nil
+	}, sdk.OnProperty("cache.enabled"))
+
+	err := container.Validate()
+	assert.ErrorContains(t, err, "bean 'flag' requires 'missing'")
+	assert.Equal(t, 1, sources)
+	assert.Zero(t, constructed)
+
+	// 预演的条件注册不保留，配置源未放入容器
+	_, err = sdk.InvokeBean[*echoA](container, "flag")
+	assert.Error(t, err)
+	assert.ErrorContains(t, container.Validate(), "bean 'flag' requires 'missing'")
+	_, err = sdk.InvokeBean[properties](container, "properties")
+	require.NoError(t, err)
+	assert.Equal(t, 3, sources)
+
+	// 已创建的配置源直接使用
+	assert.Error(t, container.Validate())
+	assert.Equal(t, 3, sources)
+}
+
+func TestInspect(t *testing.T) {
+	container := sdk.NewContainer()
+	container.Conditional(func() {
+		sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+	})
+
+	var buf bytes.Buffer
+	inspected, err := container.Inspect(&buf)
+	require.NoError(t, err)
+	assert.False(t, inspected)
+
+	t.Setenv("IOCGO_GRAPH", "mermaid")
+	inspected, err = container.Inspect(&buf)
+	require.NoError(t, err)
+	assert.True(t, inspected)
+	assert.Contains(t, buf.String(), `n0["a<br/>singleton, lazy"]`)
+	// 条件注册只在副本上预演
+	assert.Empty(t, container.Graph().Nodes)
+
+	// Run 不读取环境变量
+	require.NoError(t, container.Run())
+	assert.Len(t, container.Graph().Nodes, 1)
+	require.NoError(t, container.Stop())
+}
+
+func TestValidateCollectionCycle(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+	sdk.ProvideBean[*beanB](container, "b", func() (*beanB, error) { return &beanB{}, nil })
+	sdk.RequiresAll[*beanA](container, "b")
+	assert.NoError(t, container.Validate())
+
+	sdk.Requires[*beanB](container, "a", "b")
+	var cycle *sdk.ErrCycle
+	require.True(t, errors.As(container.Validate(), &cycle))
+	assert.Equal(t, []string{"a", "b", "a"}, cycle.Chain)
+}
+
+func TestEagerParallel(t *testing.T) {
+	container := sdk.NewContainer()
+	container.Parallelism(2)
+
+	var mu sync.Mutex
+	var created []string
+	provide := func(name string, delay time.Duration, err error) {
+		sdk.ProvideBean[*beanA](container, name, func() (*beanA, error) {
+			time.Sleep(delay)
+			mu.Lock()
+			defer mu.Unlock()
+			created = append(created, name)
+			return &beanA{}, err
+		})
+		container.Eager(name)
+	}
+
+	provide("a", 50*time.Millisecond, nil)
+	provide("b", 50*time.Millisecond, nil)
+	provide("c", 0, nil)
+	// c 经由懒加载的 lazy 依赖 a 与 b
+	sdk.ProvideBean[*beanB](container, "lazy", func() (*beanB, error) { return &beanB{}, nil })
+	sdk.Requires[*beanB](container, "c", "lazy")
+	sdk.Requires[*beanA](container, "lazy", "a")
+	sdk.Requires[*beanA](container, "lazy", "b")
+
+	now := time.Now()
+	require.NoError(t, container.Run())
+	assert.Less(t, time.Since(now), 90*time.Millisecond)
+	assert.ElementsMatch(t, []string{"a", "b"}, created[:2])
+	assert.Equal(t, "c", created[2])
+
+	container = sdk.NewContainer()
+	container.Parallelism(2)
+	created = nil
+	provide("a", 0, errors.New("a failed"))
+	provide("b", 0, errors.New("b failed"))
+	provide("c", 0, nil)
+	sdk.Requires[*beanA](container, "c", "a")
+
+	err := container.Run()
+	assert.ErrorContains(t, err, "a failed")
+	assert.ErrorContains(t, err, "b failed")
+	assert.NotContains(t, created, "c")
+}
+
+func TestInitializerOrdering(t *testing.T) {
+	var inits []string
+	initializer := func(container *sdk.Container, name string, order int) {
+		sdk.ProvideBean[sdk.Initializer](container, name, func() (sdk.Initializer, error) {
+			return sdk.InitializedWrapper(order, func(*sdk.Container) error {
+				inits = append(inits, name)
+				return nil
+			}), nil
+		})
+	}
+
+	container := sdk.NewContainer()
+	container.AddInitialized(func() error {
+		inits = append(inits, sdk.Initialized)
+		return nil
+	})
+	initializer(container, "cobra", 1000)
+	initializer(container, "migrate", -1)
+	initializer(container, "cache", 0)
+	initializer(container, "web", 0)
+	container.After("cobra", sdk.Initialized)
+	container.DependsOn("web", "migrate")
+	container.Before("migrate", "cache")
+
+	require.NoError(t, container.Run())
+	assert.Equal(t, []string{"migrate", "cache", "web", sdk.Initialized, "cobra"}, inits)
+
+	container = sdk.NewContainer()
+	initializer(container, "a", 0)
+	initializer(container, "b", 0)
+	container.After("a", "b")
+	container.Before("a", "b")
+	var cycle *sdk.ErrCycle
+	require.ErrorAs(t, container.Run(), &cycle)
+	assert.Equal(t, []string{"a", "b", "a"}, cycle.Chain)
+
+	container = sdk.NewContainer()
+	initializer(container, "a", 0)
+	container.DependsOn("a", "unknown")
+	assert.ErrorContains(t, container.Run(), "depends on unknown bean 'unknown'")
+
+	// 并发声明先后关系
+	container = sdk.NewContainer()
+	initializer(container, "a", 0)
+	initializer(container, "b", 0)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			container.DependsOn("a", "b")
+			container.After("a", "b")
+			container.Before("b", "a")
+		}()
+	}
+	_ = container.Validate()
+	wg.Wait()
+	inits = nil
+	require.NoError(t, container.Run())
+	assert.Equal(t, []string{"b", "a"}, inits)
+}
+
+func TestScope(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+
+	var closed int
+	sdk.ProvideScoped[*beanB](container, "request", "b", func(scope *sdk.Container) (*beanB, error) {
+		a, err := sdk.InvokeBean[*beanA](scope, "a")
+		scope.OnDestroy("b", func(context.Context) error { closed++; return nil })
+		return &beanB{a}, err
+	})
+	container.Alias("req.b", "b")
+
+	_, err := sdk.InvokeBean[*beanB](container, "b")
+	assert.ErrorContains(t, err, "scoped to 'request'")
+
+	s1, s2 := container.Scope("request"), container.Scope("request")
+	b1, err := sdk.InvokeBean[*beanB](s1, "req.b")
+	require.NoError(t, err)
+	b2, _ := sdk.InvokeBean[*beanB](s1, "b")
+	b3, _ := sdk.InvokeBean[*beanB](s2, "b")
+	assert.Same(t, b1, b2)
+	assert.NotSame(t, b1, b3)
+	// 父容器中的单例在子作用域间共享
+	assert.Same(t, b1.a, b3.a)
+
+	a, err := sdk.InvokeAs[*beanA](s1)
+	require.NoError(t, err)
+	assert.Same(t, b1.a, a)
+
+	require.NoError(t, s1.Close())
+	assert.Equal(t, 1, closed)
+	_, err = sdk.InvokeBean[*beanB](s1, "b")
+	assert.ErrorIs(t, err, sdk.ErrStopped)
+	require.NoError(t, s2.Close())
+	assert.Equal(t, 2, closed)
+}
+
+func TestProviderLazyOptional(t *testing.T) {
+	container := sdk.NewContainer()
+	var created int
+	sdk.ProvideTransient[*beanB](container, "transient", func() (*beanB, error) { created++; return &beanB{}, nil })
+	sdk.ProvideBean[*echoA](container, "echo", func() (*echoA, error) { return &echoA{}, nil })
+
+	provider := sdk.NewProvider[*beanB](container, "transient")
+	lazy := sdk.NewLazy[*beanB](container, "transient")
+	assert.Equal(t, 0, created)
+
+	b1, _ := provider.Get()
+	b2, _ := provider.Get()
+	assert.NotSame(t, b1, b2)
+	l1, err := lazy.Get()
+	require.NoError(t, err)
+	l2, _ := lazy.Get()
+	assert.Same(t, l1, l2)
+	assert.Equal(t, 3, created)
+
+	o, err := sdk.InvokeOptional[echo](container, "echo")
+	require.NoError(t, err)
+	assert.True(t, o.IsPresent())
+
+	o, err = sdk.InvokeOptional[echo](container, "missing")
+	require.NoError(t, err)
+	assert.False(t, o.IsPresent())
+	assert.Equal(t, "B", o.OrElse(echoB{}).Echo())
+
+	// Lazy 打破构造器之间的循环依赖
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		_ = sdk.NewLazy[*beanB](container, "b")
+		return &beanA{}, nil
+	})
+	sdk.ProvideBean[*beanB](container, "b", func() (*beanB, error) {
+		a, err := sdk.InvokeBean[*beanA](container, "a")
+		return &beanB{a}, err
+	})
+	sdk.RequiresLazy[*beanB](container, "a", "b")
+	sdk.Requires[*beanA](container, "b", "a")
+	sdk.RequiresOptional[echo](container, "b", "missing")
+	assert.NoError(t, container.Validate())
+}
+
+func TestCollections(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*echoA](container, "a", func() (*echoA, error) { return &echoA{}, nil })
+	sdk.ProvideBean[echoB](container, "b", func() (echoB, error) { return echoB{}, nil })
+	sdk.ProvideBean[*beanA](container, "other", func() (*beanA, error) { return &beanA{}, nil })
+	container.Order("b", -1)
+	container.Alias("echo.a", "a")
+
+	list, err := sdk.InvokeSlice[echo](container)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "B", list[0].Echo())
+	assert.Equal(t, "A", list[1].Echo())
+
+	m, err := sdk.InvokeMap[echo](container)
+	require.NoError(t, err)
+	assert.Len(t, m, 2)
+	assert.Equal(t, "A", m["echo.a"].Echo())
+	assert.Equal(t, "B", m["b"].Echo())
+
+	empty, err := sdk.InvokeSlice[*closer](container)
+	require.NoError(t, err)
+	assert.NotNil(t, empty)
+}
+
+type properties map[string]any
+
+func (p properties) IsSet(key string) bool { _, ok := p[key]; return ok }
+func (p properties) Get(key string) any    { return p[key] }
+func (p properties) GetString(key string) string {
+	s, _ := p[key].(string)
+	return s
+}
+func (p properties) GetStringSlice(key string) []string {
+	s, _ := p[key].([]string)
+	return s
+}
+
+func TestValue(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[properties](container, "properties", func() (properties, error) {
+		return properties{"port": "8080", "timeout": 3000, "hosts": []any{"a", "b"}, "bad": "x"}, nil
+	})
+
+	port, err := sdk.Value[int](container, "port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	timeout, err := sdk.Value[time.Duration](container, "timeout")
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Microsecond, timeout)
+
+	hosts, err := sdk.Value[[]string](container, "hosts")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	debug, err := sdk.Value[bool](container, "debug", "true")
+	require.NoError(t, err)
+	assert.True(t, debug)
+
+	_, err = sdk.Value[string](container, "missing")
+	assert.ErrorContains(t, err, "config 'missing' is not set")
+	_, err = sdk.Value[int](container, "bad")
+	assert.ErrorContains(t, err, `cannot convert "x" to int`)
+
+	// 缺失的配置项在启动时报错，而非构造时
+	sdk.ProvideBean[*echoA](container, "a", func() (*echoA, error) { return &echoA{}, nil })
+	sdk.RequiresValue[int](container, "a", "missing")
+	var invalid *sdk.ErrInvalidDependency
+	require.ErrorAs(t, container.Run(), &invalid)
+	assert.Equal(t, "missing", invalid.Dependency)
+}
+
+type dbConfig struct {
+	Driver  string        `mapstructure:"driver" validate:"required,oneof=mysql postgres"`
+	Host    string        `validate:"required"`
+	Port    int           `validate:"min=1,max=65535"`
+	Timeout time.Duration `mapstructure:"timeout" validate:"max=10s"`
+	Pool    struct {
+		Size int `mapstructure:"size" validate:"min=1"`
+	} `mapstructure:"pool"`
+	Replicas int `mapstructure:"replicas" validate:"omitempty,min=1"`
+}
+
+func TestConfig(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[properties](container, "properties", func() (properties, error) {
+		return properties{
+			"db":      map[string]any{"driver": "mysql", "host": "localhost", "port": "3306", "timeout": "5s", "pool": map[string]any{"size": 4}},
+			"invalid": map[string]any{"driver": "sqlite", "port": "x", "timeout": "1m", "pool": map[string]any{"size": -1}},
+		}, nil
+	})
+	sdk.ProvideConfig[dbConfig](container, "db", "db")
+	require.NoError(t, container.Run())
+
+	db, err := sdk.InvokeBean[*dbConfig](container, "db")
+	require.NoError(t, err)
+	assert.Equal(t, 3306, db.Port)
+	assert.Equal(t, 5*time.Second, db.Timeout)
+	assert.Equal(t, 4, db.Pool.Size)
+
+	sdk.ProvideConfig[dbConfig](container, "invalid", "invalid")
+	var invalid *sdk.ErrInvalidConfig
+	require.ErrorAs(t, container.Validate(), &invalid)
+	assert.Equal(t, []string{"invalid.port", "invalid.driver", "invalid.host", "invalid.timeout", "invalid.pool.size"}, invalid.Keys)
+
+	_, err = sdk.InvokeBean[*dbConfig](container, "invalid")
+	assert.ErrorAs(t, err, &invalid)
+
+	// 未配置的字段同样校验，omitempty 除外
+	container = sdk.NewContainer()
+	sdk.ProvideBean[properties](container, "properties", func() (properties, error) {
+		return properties{"empty": map[string]any{"driver": "mysql", "host": "localhost"}}, nil
+	})
+	sdk.ProvideConfig[dbConfig](container, "empty", "empty")
+	_, err = sdk.InvokeBean[*dbConfig](container, "empty")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []string{"empty.port", "empty.pool.size"}, invalid.Keys)
+}
+
+type recorder struct {
+	steps *[]string
+}
+
+func (r recorder) BeforeInit(name string, _ reflect.Type, bean any) (any, error) {
+	*r.steps = append(*r.steps, "before "+name)
+	return bean, nil
+}
+
+func (r recorder) AfterInit(name string, _ reflect.Type, bean any) (any, error) {
+	*r.steps = append(*r.steps, "after "+name)
+	if _, ok := bean.(echo); ok {
+		return echoB{}, nil
+	}
+	return bean, nil
+}
+
+func TestPostProcessor(t *testing.T) {
+	var steps []string
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "dep", func() (*beanA, error) { return &beanA{}, nil })
+	sdk.ProvideBean[recorder](container, "recorder", func() (recorder, error) {
+		_, err := sdk.InvokeBean[*beanA](container, "dep")
+		return recorder{&steps}, err
+	})
+	sdk.ProvideBean[echo](container, "echo", func() (echo, error) { return echoA{}, nil })
+	sdk.OnInit(container, "echo", func(echo) error {
+		steps = append(steps, "init echo")
+		return nil
+	})
+	container.Alias("greeter", "echo")
+	sdk.Decorate(container, "greeter", func(e echo) (echo, error) {
+		steps = append(steps, "decorate "+e.Echo())
+		return e, nil
+	})
+	require.NoError(t, container.Validate())
+
+	e, err := sdk.InvokeBean[echo](container, "echo")
+	require.NoError(t, err)
+	assert.Equal(t, "B", e.Echo())
+	// 后置处理器及其依赖不被处理
+	assert.Equal(t, []string{"before echo", "init echo", "after echo", "decorate B"}, steps)
+
+	sdk.Decorate(container, "dep", func(echo) (echo, error) { return nil, nil })
+	assert.ErrorContains(t, container.Validate(), "decorator of bean 'dep'")
+	// 生成代码声明装饰函数的位置
+	sdk.DecorateAt(container, "dep", "model/dep.go:12", func(echo) (echo, error) { return nil, nil })
+	assert.ErrorContains(t, container.Validate(), "\n\tin model/dep.go:12")
+}
+
+func TestErrors(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) {
+		_, err := sdk.InvokeBean[*beanB](container, "b")
+		return nil, err
+	})
+	sdk.ProvideBean[*beanB](container, "b", func() (*beanB, error) { return nil, errors.New("db unreachable") })
+	sdk.ProvideBean[*beanA](container, "c", func() (*beanA, error) {
+		_, err := sdk.InvokeBean[*beanB](container, "missing")
+		return nil, err
+	})
+	sdk.ProvideBean[echo](container, "echo.a", func() (echo, error) { return echoA{}, nil })
+	sdk.ProvideBean[echo](container, "echo.b", func() (echo, error) { return echoB{}, nil })
+
+	// 只在最内层失败的 bean 上报告
+	_, err := sdk.InvokeBean[*beanA](container, "a")
+	var failed *sdk.ErrProviderFailed
+	require.ErrorAs(t, err, &failed)
+	assert.Equal(t, "b", failed.Name)
+	assert.Equal(t, "*sdk_test.beanB", failed.Type)
+	assert.Equal(t, []string{"a", "b"}, failed.Path)
+	assert.Contains(t, failed.Location, "container_test.go:")
+	assert.EqualError(t, failed.Err, "db unreachable")
+	assert.Contains(t, sdk.FormatError(err), "cause:\n\tdb unreachable")
+
+	_, err = sdk.InvokeBean[*beanA](container, "c")
+	var notFound *sdk.ErrBeanNotFound
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, []string{"c", "missing"}, notFound.Path)
+	assert.ErrorContains(t, err, "bean 'missing' of type *sdk_test.beanB not found, path: c -> missing")
+
+	_, err = sdk.InvokeAs[echo](container)
+	var ambiguous *sdk.ErrAmbiguous
+	require.ErrorAs(t, err, &ambiguous)
+	assert.Equal(t, []string{"echo.a", "echo.b"}, ambiguous.Candidates)
+}
+
+func TestContext(t *testing.T) {
+	type key struct{}
+	container := sdk.NewContainer()
+	sdk.ProvideBeanCtx[*beanA](container, "a", func(ctx context.Context) (*beanA, error) {
+		if _, err := sdk.InvokeBean[*beanB](container, "b"); err != nil {
+			return nil, err
+		}
+		return &beanA{}, nil
+	})
+	// 依赖沿用调用方的上下文
+	sdk.ProvideBeanCtx[*beanB](container, "b", func(ctx context.Context) (*beanB, error) {
+		if ctx.Value(key{}) != "request" {
+			return nil, errors.New("context not inherited")
+		}
+		return &beanB{}, nil
+	})
+	sdk.ProvideBeanCtx[*closer](container, "slow", func(ctx context.Context) (*closer, error) {
+		<-ctx.Done()
+		return nil, errors.New("interrupted")
+	})
+	container.ProvideTimeout("slow", 50*time.Millisecond)
+
+	_, err := sdk.InvokeBeanCtx[*beanA](context.WithValue(context.Background(), key{}, "request"), container, "a")
+	require.NoError(t, err)
+
+	_, err = sdk.InvokeBean[*closer](container, "slow")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sdk.InvokeBeanCtx[*closer](ctx, container, "slow")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// 超时后才返回的实例被销毁且不再使用
+	var closed []string
+	sdk.ProvideBean[*closer](container, "late", func() (*closer, error) {
+		time.Sleep(50 * time.Millisecond)
+		container.OnDestroy("late", func(context.Context) error { closed = append(closed, "hook"); return nil })
+		return &closer{"late", &closed}, nil
+	})
+	container.ProvideTimeout("late", 10*time.Millisecond)
+	_, err = sdk.InvokeBean[*closer](container, "late")
+	var failed *sdk.ErrProviderFailed
+	require.ErrorAs(t, err, &failed)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"hook", "late"}, closed)
+}
+
+func TestRunInterruptedOnSignal(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBeanCtx[*closer](container, "slow", func(ctx context.Context) (*closer, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	container.AddInitialized(func() (err error) {
+		_, err = sdk.InvokeBean[*closer](container, "slow")
+		return
+	})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	}()
+	assert.ErrorIs(t, container.Run(syscall.SIGUSR2), context.Canceled)
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	container := sdk.NewContainer()
+	container.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+	container.Alias("alias.a", "a")
+	sdk.ProvideScoped[*beanB](container, "request", "b", func(*sdk.Container) (*beanB, error) { return &beanB{}, nil })
+
+	_, err := sdk.InvokeBean[*beanA](container, "alias.a")
+	require.NoError(t, err)
+	scope := container.Scope("request")
+	_, err = sdk.InvokeBean[*beanB](scope, "b")
+	require.NoError(t, err)
+	require.NoError(t, scope.Close())
+	require.NoError(t, container.Stop())
+
+	out := buf.String()
+	assert.Contains(t, out, `level=DEBUG msg="alias resolved" alias=alias.a bean=a`)
+	assert.Contains(t, out, `level=DEBUG msg="bean created" bean=a type=*sdk_test.beanA`)
+	assert.Contains(t, out, `level=DEBUG msg="shutdown bean" scope=request bean=b`)
+	assert.Contains(t, out, `level=DEBUG msg="container stopped" scope=request beans=1`)
+	assert.Contains(t, out, `level=INFO msg="container stopped" beans=1`)
+}
+
+func TestMetrics(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideTransient[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+	sdk.ProvideBean[*beanB](container, "b", func() (*beanB, error) { return nil, errors.New("db unreachable") })
+
+	for range 3 {
+		_, err := sdk.InvokeBean[*beanA](container, "a")
+		require.NoError(t, err)
+	}
+	_, err := sdk.InvokeBean[*beanB](container, "b")
+	require.Error(t, err)
+
+	// 代理方法的并发调用，调用统计在所有容器间共享，按增量判断
+	calls := func() (count, bucket uint64) {
+		for _, m := range container.Metrics().Methods {
+			if m.Interface == "metrics.Echo" {
+				return m.Duration.Count, m.Duration.Counts[len(m.Duration.Counts)-1]
+			}
+		}
+		return
+	}
+	count, bucket := calls()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				proxy.Invoked("metrics.Echo", "Echo")()
+			}
+		}()
+	}
+	wg.Wait()
+
+	metrics := container.Metrics()
+	require.Len(t, metrics.Beans, 2)
+	assert.Equal(t, "a", metrics.Beans[0].Name)
+	assert.Equal(t, uint64(3), metrics.Beans[0].Constructed)
+	assert.Equal(t, uint64(3), metrics.Beans[0].Duration.Count)
+	assert.Equal(t, uint64(1), metrics.Beans[1].Failed)
+
+	after, afterBucket := calls()
+	assert.Equal(t, uint64(800), after-count)
+	assert.Equal(t, uint64(800), afterBucket-bucket)
+
+	var buf bytes.Buffer
+	require.NoError(t, metrics.WritePrometheus(&buf))
+	assert.Contains(t, buf.String(), "# TYPE iocgo_bean_construction_duration_seconds histogram\n")
+	assert.Contains(t, buf.String(), `iocgo_bean_constructions_total{bean="a"} 3`)
+	assert.Contains(t, buf.String(), `iocgo_bean_construction_failures_total{bean="b"} 1`)
+	assert.Contains(t, buf.String(), `iocgo_bean_construction_duration_seconds_bucket{bean="a",le="+Inf"} 3`)
+}
+
+func TestModule(t *testing.T) {
+	var installed int
+	db := &sdk.Module{
+		Name:    "db",
+		Exports: []string{"db"},
+		Install: func(container *sdk.Container) error {
+			installed++
+			sdk.ProvideBean[*beanB](container, "pool", func() (*beanB, error) { return &beanB{}, nil })
+			sdk.ProvideBean[*beanA](container, "db", func() (*beanA, error) {
+				_, err := sdk.InvokeBean[*beanB](container, "pool")
+				return &beanA{}, err
+			})
+			return nil
+		},
+	}
+	app := &sdk.Module{
+		Name:    "app",
+		Imports: []*sdk.Module{db},
+		Install: func(container *sdk.Container) error {
+			sdk.ProvideBean[echo](container, "svc", func() (echo, error) {
+				_, err := sdk.InvokeBean[*beanA](container, "db")
+				return echoA{}, err
+			})
+			sdk.ProvideBean[echo](container, "bad", func() (echo, error) {
+				_, err := sdk.InvokeBean[*beanB](container, "pool")
+				return echoB{}, err
+			})
+			sdk.Requires[*beanB](container, "bad", "pool")
+			return nil
+		},
+	}
+
+	container := sdk.NewContainer()
+	require.NoError(t, container.Install(app, db))
+	assert.Equal(t, 1, installed)
+	assert.Equal(t, []string{"db", "app"}, container.Modules())
+
+	_, err := sdk.InvokeBean[echo](container, "svc")
+	require.NoError(t, err)
+	_, err = sdk.InvokeBean[echo](container, "bad")
+	assert.ErrorContains(t, err, "bean 'pool' is not exported by module 'db'")
+	assert.ErrorContains(t, container.Validate(), "bean 'pool' is not exported by module 'db'")
+	assert.Contains(t, container.Graph().DOT(), "subgraph \"cluster_db\" {")
+
+	// 测试中整体替换模块
+	container = sdk.NewContainer()
+	container.OverrideModule(&sdk.Module{Name: "db", Exports: []string{"db"}, Install: func(container *sdk.Container) error {
+		sdk.ProvideBean[*beanA](container, "db", func() (*beanA, error) { return &beanA{}, nil })
+		return nil
+	}})
+	require.NoError(t, container.Install(app))
+	assert.Equal(t, 1, installed)
+	_, err = sdk.InvokeBean[echo](container, "svc")
+	require.NoError(t, err)
+
+	container = sdk.NewContainer()
+	require.NoError(t, container.Install(&sdk.Module{Name: "empty", Exports: []string{"db"}}))
+	assert.ErrorContains(t, container.Run(), "module 'empty' exports bean 'db' that is not registered")
+	// 不同模块注册同名的 bean，即使 Install 忽略了注册的错误
+	container = sdk.NewContainer()
+	err = container.Install(db, &sdk.Module{Name: "cache", Install: func(container *sdk.Container) error {
+		sdk.ProvideBean[*beanB](container, "pool", func() (*beanB, error) { return &beanB{}, nil })
+		return nil
+	}})
+	assert.ErrorIs(t, err, sdk.ErrBeanExists)
+	assert.ErrorContains(t, err, "install module 'cache': bean already exists: 'pool' in module 'cache', registered by module 'db'")
+	assert.Equal(t, []string{"db"}, container.Modules())
+
+	// 安装失败的模块可以再次安装
+	attempts := 0
+	flaky := &sdk.Module{Name: "flaky", Install: func(container *sdk.Container) error {
+		if attempts++; attempts == 1 {
+			return errors.New("config not ready")
+		}
+		return sdk.ProvideBean[*beanA](container, "flaky", func() (*beanA, error) { return &beanA{}, nil })
+	}}
+	container = sdk.NewContainer()
+	assert.ErrorContains(t, container.Install(flaky), "config not ready")
+	require.NoError(t, container.Install(flaky))
+	_, err = sdk.InvokeBean[*beanA](container, "flaky")
+	assert.NoError(t, err)
+}
+
+func TestFactory(t *testing.T) {
+	type factory = func(name string) (*closer, error)
+
+	var closed []string
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+	sdk.ProvideFactory(container, "*sdk_test.closer", func(name string) (*closer, error) {
+		if _, err := sdk.InvokeBean[*beanA](container, "a"); err != nil {
+			return nil, err
+		}
+		return &closer{name, &closed}, nil
+	})
+	assert.Equal(t, "sdk_test.closerFactory", sdk.FactoryName("*sdk_test.closer"))
+
+	create, err := sdk.InvokeBean[factory](container, sdk.FactoryName("*sdk_test.closer"))
+	require.NoError(t, err)
+	c1, err := create("db")
+	require.NoError(t, err)
+	c2, err := create("cache")
+	require.NoError(t, err)
+	assert.NotSame(t, c1, c2)
+	assert.Equal(t, "cache", c2.name)
+
+	assert.Panics(t, func() { sdk.ProvideFactory(container, "b", "not a function") })
+}
+
+func TestAlias(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+
+	require.NoError(t, container.Alias("x", "a"))
+	require.NoError(t, container.Alias("x", "a"))
+	require.NoError(t, container.Alias("y", "x"))
+	assert.ErrorIs(t, container.Alias("x", "b"), sdk.ErrAliasExists)
+
+	var cycle *sdk.ErrAliasCycle
+	require.ErrorAs(t, container.Alias("a", "y"), &cycle)
+	assert.Equal(t, []string{"a", "y", "x", "a"}, cycle.Chain)
+
+	// 子作用域中的别名不影响父容器
+	scope := container.Scope("request")
+	require.NoError(t, scope.Alias("z", "y"))
+	assert.Equal(t, map[string]string{"x": "a", "y": "x", "z": "y"}, scope.Aliases())
+	assert.Equal(t, map[string]string{"x": "a", "y": "x"}, container.Aliases())
+	_, err := sdk.InvokeBean[*beanA](scope, "z")
+	require.NoError(t, err)
+
+	require.NoError(t, container.Unalias("y"))
+	assert.ErrorIs(t, container.Unalias("y"), sdk.ErrAliasNotFound)
+	_, err = sdk.InvokeBean[*beanA](container, "y")
+	assert.Error(t, err)
+
+	// 条件注册中的别名冲突由 Run 返回
+	container.ConditionalErr(func() error { return container.Alias("x", "b") })
+	assert.ErrorIs(t, container.Run(), sdk.ErrAliasExists)
+}
+
+func TestProvideBeanExists(t *testing.T) {
+	container := sdk.NewContainer()
+	require.NoError(t, sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil }))
+	err := sdk.ProvideTransient[*beanB](container, "a", func() (*beanB, error) { return &beanB{}, nil })
+	assert.ErrorIs(t, err, sdk.ErrBeanExists)
+	assert.Contains(t, err.Error(), "container_test.go:")
+
+	// 重复注册不修改已注册的 bean
+	a, err := sdk.InvokeBean[*beanA](container, "a")
+	require.NoError(t, err)
+	assert.NotNil(t, a)
+	assert.NoError(t, container.Validate())
+
+	// 直接通过 do 注册的服务
+	do.ProvideNamedValue(container.Inject(), "b", &beanB{})
+	assert.ErrorIs(t, sdk.ProvideBean[*beanB](container, "b", func() (*beanB, error) { return &beanB{}, nil }), sdk.ErrBeanExists)
+
+	require.NoError(t, sdk.OverrideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil }))
+	replaced, err := sdk.InvokeBean[*beanA](container, "a")
+	require.NoError(t, err)
+	assert.NotSame(t, a, replaced)
+}
+
+func TestInvokeProvidedByDo(t *testing.T) {
+	container := sdk.NewContainer()
+	do.ProvideNamedValue(container.Inject(), "a", &beanA{})
+
+	a, err := sdk.InvokeBean[*beanA](container, "a")
+	require.NoError(t, err)
+	assert.NotNil(t, a)
+
+	optional, err := sdk.InvokeOptional[*beanA](container.Scope("request"), "a")
+	require.NoError(t, err)
+	assert.True(t, optional.IsPresent())
+
+	_, err = sdk.InvokeBean[*beanA](container, "missing")
+	var notFound *sdk.ErrBeanNotFound
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	container := sdk.NewContainer()
+	sdk.ProvideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil })
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := "b" + strconv.Itoa(i)
+			assert.NoError(t, sdk.ProvideBean[*beanB](container, name, func() (*beanB, error) { return &beanB{}, nil }))
+			assert.NoError(t, container.Alias("alias."+name, name))
+			assert.NoError(t, sdk.OverrideBean[*beanA](container, "a", func() (*beanA, error) { return &beanA{}, nil }))
+			for range 10 {
+				_, err := sdk.InvokeBean[*beanB](container, "alias."+name)
+				assert.NoError(t, err)
+				_, err = sdk.InvokeBean[*beanA](container, "a")
+				assert.NoError(t, err)
+				_ = container.Aliases()
+			}
+			assert.NoError(t, container.Unalias("alias."+name))
+		}()
+	}
+	wg.Wait()
+	assert.Empty(t, container.Aliases())
+}
+
+type greeter interface {
+	Greet() string
+	Check() error
+}
+
+type (
+	greeting struct{ text string }
+	// 与生成的间接实现一致
+	greeterRefresh struct{ get func() (greeter, error) }
+	app            struct{ greeter greeter }
+)
+
+func (g *greeting) Greet() string { return g.text }
+func (g *greeting) Check() error  { return nil }
+
+func (g *greeterRefresh) Greet() string {
+	current, err := g.get()
+	if err != nil {
+		panic(err)
+	}
+	return current.Greet()
+}
+
+func (g *greeterRefresh) Check() (err error) {
+	current, err := g.get()
+	if err != nil {
+		return
+	}
+	return current.Check()
+}
+
+func init() {
+	proxy.RegRefresh[greeter](func(get func() (greeter, error)) greeter { return &greeterRefresh{get} })
+}
+
+func TestRefresh(t *testing.T) {
+	var closed []string
+	container := sdk.NewContainer()
+	props := properties{"greeting.text": "hello"}
+	sdk.ProvideBean[properties](container, "properties", func() (properties, error) { return props, nil })
+	sdk.ProvideBean[*closer](container, "db", func() (*closer, error) { return &closer{"db1", &closed}, nil })
+
+	built := 0
+	container.Refreshable("greeter")
+	sdk.ProvideBean[greeter](container, "greeter", func() (greeter, error) {
+		built++
+		db, err := sdk.InvokeBean[*closer](container, "db")
+		if err != nil {
+			return nil, err
+		}
+		text, err := sdk.Value[string](container, "greeting.text")
+		// 销毁方法在 refresh 的锁外执行，可以获取 bean
+		container.OnDestroy("greeter", func(context.Context) error {
+			if _, dErr := sdk.InvokeBean[properties](container, "properties"); !errors.Is(dErr, sdk.ErrStopped) {
+				return dErr
+			}
+			return nil
+		})
+		return &greeting{text + " " + db.name}, err
+	})
+	sdk.RequiresValue[string](container, "greeter", "greeting.text")
+	sdk.ProvideBean[*app](container, "app", func() (*app, error) {
+		g, err := sdk.InvokeBean[greeter](container, "greeter")
+		return &app{g}, err
+	})
+
+	a, err := sdk.InvokeBean[*app](container, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "hello db1", a.greeter.Greet())
+
+	// 替换依赖后关闭旧实例，依赖方通过间接实现使用新实例
+	sdk.OverrideBean[*closer](container, "db", func() (*closer, error) { return &closer{"db2", &closed}, nil })
+	assert.Equal(t, []string{"db1"}, closed)
+	assert.Equal(t, "hello db2", a.greeter.Greet())
+	assert.Equal(t, 2, built)
+
+	props["greeting.text"] = "hi"
+	require.NoError(t, container.RefreshConfig("server"))
+	assert.Equal(t, 2, built)
+	require.NoError(t, container.RefreshConfig("greeting"))
+	assert.Equal(t, "hi db2", a.greeter.Greet())
+	assert.Equal(t, 3, built)
+
+	// 构造器中可以刷新其它 bean
+	sdk.ProvideTransient[*beanB](container, "reloader", func() (*beanB, error) {
+		return &beanB{}, container.Refresh("greeter")
+	})
+	_, err = sdk.InvokeBean[*beanB](container, "reloader")
+	require.NoError(t, err)
+	assert.Equal(t, 4, built)
+	assert.Equal(t, "hi db2", a.greeter.Greet())
+
+	// 获取当前实例失败时由间接实现返回错误
+	err = sdk.OverrideBean[*closer](container, "db", func() (*closer, error) { return nil, errors.New("db unreachable") })
+	assert.ErrorContains(t, err, "db unreachable")
+	assert.ErrorContains(t, a.greeter.Check(), "db unreachable")
+	require.NoError(t, sdk.OverrideBean[*closer](container, "db", func() (*closer, error) { return &closer{"db3", &closed}, nil }))
+	assert.NoError(t, a.greeter.Check())
+	assert.Equal(t, "hi db3", a.greeter.Greet())
+
+	// 旧实例被不会重新创建的 bean 持有时替换仍然生效，并报告持有方
+	sdk.ProvideBean[*beanA](container, "repo", func() (*beanA, error) {
+		_, err := sdk.InvokeBean[*closer](container, "db")
+		return &beanA{}, err
+	})
+	_, err = sdk.InvokeBean[*beanA](container, "repo")
+	require.NoError(t, err)
+	err = sdk.OverrideBean[*closer](container, "db", func() (*closer, error) { return &closer{"db4", &closed}, nil })
+	assert.ErrorIs(t, err, sdk.ErrBeanHeld)
+	assert.ErrorContains(t, err, "'db' is held by 'repo'")
+	assert.Equal(t, "hi db4", a.greeter.Greet())
+
+	require.NoError(t, container.Stop())
+	assert.Equal(t, []string{"db1", "db2", "db3", "db4"}, closed)
+}
@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"context"
+	"time"
+)
+
+// ContextOf 获取当前解析的上下文，在构造器中调用时为创建该 bean 的上下文（含其期限），
+// 否则为容器的上下文，容器 Stop 或 Run 收到信号时取消
+func ContextOf(container *Container) context.Context {
+	if threadLocal.Ex(false) {
+		if ctx := threadLocal.Load().context(); ctx != nil {
+			return ctx
+		}
+	}
+	return container.ctx
+}
+
+// InvokeBeanCtx 在 ctx 中获取 bean，期间创建的 bean 及其依赖沿用 ctx，ctx 取消后不再创建新的实例
+func InvokeBeanCtx[T any](ctx context.Context, container *Container, name string) (T, error) {
+	value, leave := enter()
+	defer leave()
+
+	prev := value.swap(ctx)
+	defer value.swap(prev)
+	return InvokeBean[T](container, name)
+}
+
+// ProvideBeanCtx 注册需要上下文的 bean，provider 的参数为创建该 bean 的上下文
+func ProvideBeanCtx[T any](container *Container, name string, provider func(context.Context) (T, error)) error {
+	return ProvideBean(container, name, func() (T, error) {
+		return provider(ContextOf(container))
+	})
+}
+
+// ProvideTimeout 设置创建 bean 的期限，超时后取消传给构造器的上下文。
+// 构造器须自行响应上下文的取消
+func (c *Container) ProvideTimeout(name string, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bean(c.resolve(name)).timeout = timeout
+}
+
+// 创建 bean 的上下文，子作用域中沿用父容器设置的期限
+func (c *Container) contextOf(name string) (context.Context, context.CancelFunc) {
+	ctx := ContextOf(c)
+	for container := c; container != nil; container = container.parent {
+		container.mu.Lock()
+		b, ok := container.beans[name]
+		container.mu.Unlock()
+		if ok && b.timeout > 0 {
+			return context.WithTimeout(ctx, b.timeout)
+		}
+	}
+	return ctx, func() {}
+}
@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	run "runtime"
+	"slices"
+)
+
+// Parallelism 设置 Run 时并发实例化非懒加载 bean 的数量，默认为 GOMAXPROCS
+func (c *Container) Parallelism(n int) {
+	c.parallelism = n
+}
+
+// 按声明的依赖与 Before/After 构建非懒加载 bean 的 DAG，无依赖关系的 bean 并发实例化，依赖方总在其依赖完成后实例化。
+// 出现错误后不再启动新的 bean，等待进行中的 bean 完成后返回所有错误
+func (c *Container) eagerInit() error {
+	c.mu.Lock()
+	var names []string
+	for name, b := range c.beans {
+		if b.eager && b.typ != nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	if errs := c.cycles(names); len(errs) > 0 {
+		c.mu.Unlock()
+		return errors.Join(errs...)
+	}
+
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	edge := func(from, to string) {
+		if b, ok := c.beans[from]; !ok || !b.eager || b.typ == nil || from == to || slices.Contains(dependents[from], to) {
+			return
+		}
+		dependents[from] = append(dependents[from], to)
+		pending[to]++
+	}
+
+	for _, name := range names {
+		for _, dep := range c.eagerDeps(name) {
+			edge(dep, name)
+		}
+		// Before/After 声明的先后关系
+		if o, ok := c.orders[name]; ok {
+			for _, n := range o.after {
+				edge(c.resolve(n), name)
+			}
+			for _, n := range o.before {
+				if b, ok := c.beans[c.resolve(n)]; ok && b.eager {
+					edge(name, c.resolve(n))
+				}
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	limit := elseOf(c.parallelism > 0, c.parallelism, run.GOMAXPROCS(0))
+	ready := slices.DeleteFunc(slices.Clone(names), func(name string) bool { return pending[name] > 0 })
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	var (
+		errs    []error
+		running int
+		results = make(chan result)
+	)
+
+	for len(ready) > 0 || running > 0 {
+		for len(ready) > 0 && running < limit && len(errs) == 0 {
+			name := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				_, err := invoke[any](c, name, true)
+				results <- result{name, err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("eager bean '%s': %w", r.name, r.err))
+			continue
+		}
+
+		for _, name := range dependents[r.name] {
+			if pending[name]--; pending[name] == 0 {
+				ready = append(ready, name)
+			}
+		}
+		slices.Sort(ready)
+	}
+
+	if len(errs) == 0 && slices.ContainsFunc(names, func(name string) bool { return pending[name] > 0 }) {
+		return cycleOf(dependents, pending)
+	}
+	return errors.Join(errs...)
+}
+
+// 查找 bean 直接或经由懒加载 bean 间接依赖的非懒加载 bean
+func (c *Container) eagerDeps(name string) (deps []string) {
+	visited := map[string]bool{name: true}
+	var visit func(name string)
+	visit = func(name string) {
+		b, ok := c.beans[name]
+		if !ok {
+			return
+		}
+		for _, dep := range c.dependencies(b) {
+			n := c.resolve(dep.name)
+			if visited[n] || dep.lazy {
+				continue
+			}
+			visited[n] = true
+			if d, ok := c.beans[n]; ok && d.eager && d.typ != nil {
+				deps = append(deps, n)
+				continue
+			}
+			visit(n)
+		}
+	}
+	visit(name)
+	slices.Sort(deps)
+	return
+}
@@ -0,0 +1,191 @@
+package sdk
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/do/v2"
+)
+
+// ErrCycle 循环依赖错误
+type ErrCycle struct {
+	// 重复请求的 bean
+	Name string
+	// 完整的依赖链，末尾即为重复请求的 bean
+	Chain []string
+	// Chain 中每个 bean 被请求时的代码位置
+	Locations []string
+}
+
+func (e *ErrCycle) Error() string {
+	return "circular dependency occurs:\n" + strings.TrimSuffix(join(e.Chain, e.Name, e.Locations), "\n")
+}
+
+// Resolution 获取 bean 失败时的解析信息
+type Resolution struct {
+	// 请求的 bean 名称，按类型获取时为空
+	Name string
+	// 请求的类型
+	Type string
+	// 从根 bean 开始的解析路径，末尾为请求的 bean
+	Path []string
+	// Path 中每个 bean 被请求时的代码位置
+	Locations []string
+}
+
+func (r Resolution) path() string {
+	if len(r.Path) < 2 {
+		return ""
+	}
+	return ", path: " + strings.Join(r.Path, " -> ")
+}
+
+// ErrBeanNotFound 请求的 bean 未注册，或在当前作用域中不可见
+type ErrBeanNotFound struct {
+	Resolution
+	// bean 所属的作用域，只能在该作用域中获取
+	Scope string
+}
+
+func (e *ErrBeanNotFound) Error() string {
+	return e.message() + e.path()
+}
+
+func (e *ErrBeanNotFound) message() string {
+	msg := fmt.Sprintf("no bean assignable to %s", e.Type)
+	if e.Name != "" {
+		msg = fmt.Sprintf("bean '%s' of type %s not found", e.Name, e.Type)
+	}
+	if e.Scope != "" {
+		msg += fmt.Sprintf(", it is scoped to '%s', invoke it with container.Scope(\"%s\")", e.Scope, e.Scope)
+	}
+	return msg
+}
+
+func (e *ErrBeanNotFound) Unwrap() error {
+	return do.ErrServiceNotFound
+}
+
+// ErrAmbiguous 按类型获取时存在多个候选 bean
+type ErrAmbiguous struct {
+	Resolution
+	// 可赋值给该类型的候选 bean
+	Candidates []string
+}
+
+func (e *ErrAmbiguous) Error() string {
+	return fmt.Sprintf("ambiguous bean of type %s, %d candidates found: %s", e.Type, len(e.Candidates), strings.Join(e.Candidates, ", ")) + e.path()
+}
+
+// ErrProviderFailed bean 的构造器返回错误，依赖创建失败时只在最内层的 bean 上报告
+type ErrProviderFailed struct {
+	Resolution
+	// 失败的构造器位置，即注解构造器或 ProvideBean 的调用位置
+	Location string
+	Err      error
+}
+
+func (e *ErrProviderFailed) Error() string {
+	msg := fmt.Sprintf("provider of bean '%s' failed: %v", e.Name, e.Err)
+	if e.Location != "" {
+		msg += "\n\tin " + e.Location
+	}
+	return msg
+}
+
+func (e *ErrProviderFailed) Unwrap() error {
+	return e.Err
+}
+
+// ErrInvalidDependency 校验时发现的无效依赖：未注册或类型不匹配
+type ErrInvalidDependency struct {
+	// 声明依赖的 bean
+	Bean string
+	// 依赖的 bean 名称
+	Dependency string
+	// 依赖声明的代码位置，即注解构造器
+	Location string
+	Err      error
+}
+
+func (e *ErrInvalidDependency) Error() string {
+	msg := fmt.Sprintf("bean '%s' requires '%s': %v", e.Bean, e.Dependency, e.Err)
+	if e.Location != "" {
+		msg += "\n\tin " + e.Location
+	}
+	return msg
+}
+
+func (e *ErrInvalidDependency) Unwrap() error {
+	return e.Err
+}
+
+// ErrInvalidConfig 配置结构绑定失败，包含所有无法转换或未通过校验的配置项
+type ErrInvalidConfig struct {
+	// 配置结构绑定的前缀
+	Prefix string
+	// 无效配置项的完整 key
+	Keys []string
+	// 与 Keys 一一对应的原因
+	Reasons []string
+}
+
+func (e *ErrInvalidConfig) Error() string {
+	msg := fmt.Sprintf("invalid config '%s', %d key(s):", e.Prefix, len(e.Keys))
+	for i, key := range e.Keys {
+		msg += fmt.Sprintf("\n\t%s: %s", key, e.Reasons[i])
+	}
+	return msg
+}
+
+func (e *ErrInvalidConfig) add(key, reason string) {
+	if !slices.Contains(e.Keys, key) {
+		e.Keys = append(e.Keys, key)
+		e.Reasons = append(e.Reasons, reason)
+	}
+}
+
+// FormatError 将容器错误格式化为多行文本用于日志，展开 errors.Join 的各个错误，并列出解析路径与构造器位置
+func FormatError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var lines []string
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, ee := range e.Unwrap() {
+			lines = append(lines, FormatError(ee))
+		}
+		return strings.Join(lines, "\n")
+	case *ErrProviderFailed:
+		lines = append(lines, fmt.Sprintf("provider of bean '%s' failed", e.Name), "type: "+e.Type)
+		if e.Location != "" {
+			lines = append(lines, "constructor: "+e.Location)
+		}
+		lines = append(lines, e.Resolution.lines()...)
+		lines = append(lines, "cause:", indent(FormatError(e.Err)))
+	case *ErrBeanNotFound:
+		lines = append(lines, e.message())
+		lines = append(lines, e.Resolution.lines()...)
+	case *ErrAmbiguous:
+		lines = append(lines, fmt.Sprintf("ambiguous bean of type %s", e.Type), "candidates:")
+		lines = append(lines, indent(strings.Join(e.Candidates, "\n")))
+		lines = append(lines, e.Resolution.lines()...)
+	default:
+		return err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r Resolution) lines() []string {
+	if len(r.Path) < 2 {
+		return nil
+	}
+	return []string{"path:", indent(strings.TrimSuffix(join(r.Path, "", r.Locations), "\n"))}
+}
+
+func indent(str string) string {
+	return "\t" + strings.ReplaceAll(str, "\n", "\n\t")
+}
@@ -0,0 +1,23 @@
+package sdk
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FactoryName 辅助注入的工厂 bean 名称，为去掉指针前缀的 bean 名称加 Factory 后缀。
+// 在同一包中声明 `type RepoFactory = func(tenant string) (*Repo, error)`，该类型的构造器参数即按此名称注入
+func FactoryName(name string) string {
+	return strings.TrimPrefix(name, "*") + "Factory"
+}
+
+// ProvideFactory 注册名为 FactoryName(name) 的工厂 bean，factory 的参数由调用方传入，
+// 其余依赖在每次调用时从容器获取，创建的实例不是 bean，不执行后置处理与销毁方法
+func ProvideFactory[F any](container *Container, name string, factory F) error {
+	if typeOf[F]().Kind() != reflect.Func {
+		panic("the factory of bean '" + name + "' must be a function")
+	}
+	return ProvideBean(container, FactoryName(name), func() (F, error) {
+		return factory, nil
+	})
+}
@@ -0,0 +1,35 @@
+package annotation
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+type Config struct {
+	Prefix string `annotation:"name=prefix,default="`
+	N      string `annotation:"name=name,default="`
+	Alias  string `annotation:"name=alias,default="`
+}
+
+var _ M = (*Config)(nil)
+
+func (Config) Name() string {
+	return "config"
+}
+
+func (c Config) Match(node ast.Node) (err error) {
+	if c.Prefix == "" {
+		return fmt.Errorf("please specify the config prefix")
+	}
+
+	if spec, ok := node.(*ast.TypeSpec); !ok {
+		err = fmt.Errorf("the position of the `@Config` annotation is incorrect, needed `type T struct`")
+	} else if _, ok = spec.Type.(*ast.StructType); !ok || spec.TypeParams != nil {
+		err = fmt.Errorf("the `@Config` annotation needed a non-generic struct type, but got %s", spec.Name.Name)
+	}
+	return
+}
+
+func (c Config) As() (_ M) {
+	return
+}
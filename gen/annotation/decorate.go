@@ -0,0 +1,28 @@
+package annotation
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+type Decorate struct {
+	Target string `annotation:"name=target,default="`
+}
+
+var _ M = (*Decorate)(nil)
+
+func (Decorate) Name() string {
+	return "decorate"
+}
+
+func (d Decorate) Match(node ast.Node) (err error) {
+	fd, ok := node.(*ast.FuncDecl)
+	if !ok || MethodReceiver(fd) != "" || fd.Type.Params.NumFields() != 1 {
+		return fmt.Errorf("the position of the `@Decorate` annotation is incorrect, needed `func(T) T` or `func(T) (T, error)`")
+	}
+	return
+}
+
+func (d Decorate) As() (_ M) {
+	return
+}
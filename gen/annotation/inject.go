@@ -3,6 +3,10 @@ package annotation
 import (
 	"fmt"
 	"go/ast"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 	"unicode"
 )
 
@@ -11,13 +15,74 @@ type Inject struct {
 	N          string `annotation:"name=name,default="`
 	Alias      string `annotation:"name=alias,default="`
 	Singleton  bool   `annotation:"name=singleton,default=true"`
+	Primary    bool   `annotation:"name=primary,default=false"`
 	Initialize string `annotation:"name=init,default="`
+	Destroy    string `annotation:"name=destroy,default="`
 	Qualifier  string `annotation:"name=qualifier,default="`
 	Config     string `annotation:"name=config,default="`
+	Profile    string `annotation:"name=profile,default="`
+	Property   string `annotation:"name=property,default="`
+	Missing    string `annotation:"name=missing,default="`
+	DependsOn  string `annotation:"name=dependsOn,default="`
+	Before     string `annotation:"name=before,default="`
+	After      string `annotation:"name=after,default="`
+	Scope      string `annotation:"name=scope,default="`
+	Order      int    `annotation:"name=order,default=0"`
+	Values     string `annotation:"name=values,default="`
+	Timeout    string `annotation:"name=timeout,default="`
+	Assisted   string `annotation:"name=assisted,default="`
+	Refresh    bool   `annotation:"name=refresh,default=false"`
 }
 
 var _ M = (*Inject)(nil)
 
+// 配置项注入：`[参数下标]:key` 或 `[参数下标]:key:默认值`，多个以逗号分隔
+var (
+	valueRegexp  = regexp.MustCompile(`\[(\d+)]:\s*([^:,\[\s]+)(?::([^\[]*))?`)
+	valuesRegexp = regexp.MustCompile(`^\s*(\[\d+]:\s*[^:,\[\s]+(:[^\[]*)?\s*,?\s*)+$`)
+
+	// 辅助注入：`[参数下标]`，多个以逗号分隔
+	assistedRegexp = regexp.MustCompile(`^\s*\[\d+](\s*,\s*\[\d+])*\s*$`)
+)
+
+// AssistedOf 解析辅助注入，返回由工厂调用方传入的参数下标
+func (i Inject) AssistedOf() map[int]bool {
+	assisted := make(map[int]bool)
+	for _, idx := range strings.Split(i.Assisted, ",") {
+		if n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(idx), "[]")); err == nil {
+			assisted[n] = true
+		}
+	}
+	return assisted
+}
+
+// TimeoutOf 解析创建实例的期限，须为正的时长
+func (i Inject) TimeoutOf() (time.Duration, error) {
+	d, err := time.ParseDuration(i.Timeout)
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("non-positive duration: %s", i.Timeout)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("the `@Inject(timeout)` value needs to be a positive duration like `5s`: %w", err)
+	}
+	return d, nil
+}
+
+// ValuesOf 解析配置项注入，返回参数下标对应的 key 与默认值
+func (i Inject) ValuesOf() map[int][]string {
+	values := make(map[int][]string)
+	for _, match := range valueRegexp.FindAllStringSubmatchIndex(i.Values, -1) {
+		idx, _ := strconv.Atoi(i.Values[match[2]:match[3]])
+		values[idx] = []string{i.Values[match[4]:match[5]]}
+		// 存在默认值
+		if match[6] != -1 {
+			def := strings.TrimSpace(i.Values[match[6]:match[7]])
+			values[idx] = append(values[idx], strings.TrimSpace(strings.TrimSuffix(def, ",")))
+		}
+	}
+	return values
+}
+
 func (Inject) Name() string {
 	return "inject"
 }
@@ -28,6 +93,49 @@ func (i Inject) Match(node ast.Node) (err error) {
 		return
 	}
 
+	if i.Destroy != "" {
+		if !unicode.IsUpper([]rune(i.Destroy)[0]) {
+			err = fmt.Errorf("the `@Inject(destroy)` value needs to start with a capital case")
+			return
+		}
+		if !i.Singleton {
+			err = fmt.Errorf("the `@Inject(destroy)` is not supported by transient (singleton=\"false\") bean")
+			return
+		}
+	}
+
+	if i.Scope != "" && (!i.IsLazy || !i.Singleton) {
+		err = fmt.Errorf("the `@Inject(scope)` bean is created once per scope, `lazy=\"false\"` and `singleton=\"false\"` are not supported")
+		return
+	}
+
+	if i.Values != "" && !valuesRegexp.MatchString(i.Values) {
+		err = fmt.Errorf("the `@Inject(values)` value needs to be like `[0]:server.port:8080, [1]:server.debug`")
+		return
+	}
+
+	if i.Assisted != "" {
+		if !assistedRegexp.MatchString(i.Assisted) {
+			err = fmt.Errorf("the `@Inject(assisted)` value needs to be like `[0], [2]`")
+			return
+		}
+		if i.Initialize != "" || i.Destroy != "" || i.Scope != "" || i.Timeout != "" || !i.Singleton {
+			err = fmt.Errorf("the `@Inject(assisted)` bean is created by factory, `init`, `destroy`, `scope`, `timeout` and `singleton=\"false\"` are not supported")
+			return
+		}
+	}
+
+	if i.Refresh && (i.Scope != "" || i.Assisted != "" || !i.Singleton) {
+		err = fmt.Errorf("the `@Inject(refresh)` bean is rebuilt in the container, `scope`, `assisted` and `singleton=\"false\"` are not supported")
+		return
+	}
+
+	if i.Timeout != "" {
+		if _, err = i.TimeoutOf(); err != nil {
+			return
+		}
+	}
+
 	if _, ok := node.(*ast.FuncDecl); !ok {
 		err = fmt.Errorf("the position of the `@Inject` annotation is incorrect, needed is function (ast.FuncDecl)")
 	}
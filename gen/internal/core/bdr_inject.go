@@ -2,9 +2,13 @@ package core
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	annotations "github.com/iocgo/sdk/gen/annotation"
+	"go/ast"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
@@ -18,6 +22,9 @@ type Imported struct {
 }
 
 var (
+	// 延迟或可选获取 bean 的参数类型
+	wrappers = []string{"Provider", "Lazy", "Optional"}
+
 	iocTemplate = `package {{ .package }}
 
 import (
@@ -31,7 +38,7 @@ func Injects(container *sdk.Container) error {
 	// Registered container
 	//
 {{- range $code := .codes}}
-	{{$code}}
+{{$code}}
 {{ end }}
 
 	return nil
@@ -51,7 +58,16 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 		pkg     = ""
 		imports []Imported
 		codes,
-		activated []string
+		activated,
+		decorates []string
+
+		// refresh bean 的间接实现
+		refreshes = make(map[string][]byte)
+
+		// 生成时检查循环依赖
+		aliases   = make(map[string]string)
+		locations = make(map[string]string)
+		graph     = make(map[string][]string)
 	)
 
 	for node, convertors := range proc.mapping {
@@ -61,6 +77,52 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 		}
 
 		for _, convert := range convertors {
+			// 配置结构
+			if convert.As("config") {
+				config := convert.tag.(annotations.Config)
+				spec := convert.node.(*ast.TypeSpec)
+				iocClass := Or(config.N == "", "*"+convert.ImportPath()+"."+spec.Name.Name, config.N)
+
+				var buf strings.Builder
+				if n := config.Alias; n != "" {
+					aliases[n] = iocClass
+					buf.WriteString(fmt.Sprintf("if err := container.Alias(\"%s\", \"%s\"); err != nil {\n\treturn err\n}\n", n, iocClass))
+				}
+				buf.WriteString(fmt.Sprintf("if err := sdk.ProvideConfig[%s](container, \"%s\", \"%s\"); err != nil {\n\treturn err\n}", spec.Name.Name, iocClass, config.Prefix))
+				codes = append(codes, buf.String())
+				continue
+			}
+
+			// 装饰函数，未指定 target 时装饰参数类型对应的 bean
+			if convert.As("decorate") {
+				args := convert.ExtractArguments(lookup, convert.node)
+				returns := convert.ExtractReturns(lookup, convert.node)
+				if len(returns) == 0 || len(returns) > 2 || returns[0].String() != args[0].String() || len(returns) == 2 && returns[1].Interface != "error" {
+					panic("the decorate function must be `func(T) T` or `func(T) (T, error)`: " + convert.GetAstName())
+				}
+
+				importPath := convert.ImportPath()
+				if alias := args[0].Interface.Alias(); alias != "" {
+					if ip, ok := lookup.FindImportByAlias(alias); ok {
+						importPath = ip
+						if ip != "github.com/iocgo/sdk" {
+							imports, _ = Import(imports, alias, ip)
+						}
+					}
+				}
+
+				target := Or(args[0].IsPointer, "*", "") + importPath + "." + args[0].Interface.Ext()
+				if n := convert.tag.(annotations.Decorate).Target; n != "" {
+					target = n
+				}
+				meta := node.Meta()
+				// 装饰函数的位置，用于错误信息
+				location := fmt.Sprintf("%s:%d", filepath.Join(meta.Dir(), meta.FileName()), lookup.GetFSet().Position(convert.node.Pos()).Line)
+				decorates = append(decorates, fmt.Sprintf("sdk.DecorateAt(container, \"%s\", %s, func(bean %s) (%s, error) { return %s(bean)%s })",
+					target, strconv.Quote(location), args[0].String(), args[0].String(), convert.GetAstName(), Or(len(returns) == 1, ", nil", "")))
+				continue
+			}
+
 			if !convert.As("inject") {
 				continue
 			}
@@ -109,20 +171,72 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 			}
 		returnLabel:
 
+			// 辅助注入时注册的是工厂 bean，与 sdk.FactoryName 一致
+			assisted := inject.AssistedOf()
+			product := iocClass
+			beanName := Or(inject.Assisted != "", strings.TrimPrefix(product, "*")+"Factory", product)
 			results, padding := joinReturn(returns)
-			if !inject.IsLazy {
-				activated = append(activated, fmt.Sprintf("container.AddInitialized(func() (err error) {\n\t_, err = sdk.InvokeBean[%s](container, \"%s\")\n\treturn })", returns[0].String(), iocClass))
+			meta := node.Meta()
+			location := fmt.Sprintf("%s:%d",
+				filepath.Join(meta.Dir(), meta.FileName()),
+				lookup.GetFSet().Position(convert.node.Pos()).Line,
+			)
+			locations[beanName] = location
+			conditions := conditionsOf(inject)
+			if !inject.IsLazy && len(conditions) == 0 {
+				activated = append(activated, fmt.Sprintf("container.Eager(\"%s\")", beanName))
 			}
 
 			pos := 1
+			var requires []string
+			// 构造器位置，用于错误信息
+			buf.WriteString(fmt.Sprintf("container.Locate(\"%s\", %s)\n", beanName, strconv.Quote(location)))
 			// 组件分配别名
 			if n := inject.Alias; n != "" {
-				buf.WriteString(fmt.Sprintf("container.Alias(\"%s\", \"%s\")\n", n, iocClass))
+				aliases[n] = beanName
+				buf.WriteString(fmt.Sprintf("if err := container.Alias(\"%s\", \"%s\"); err != nil {\n\treturn err\n}\n", n, beanName))
+			}
+			// 同类型存在多个组件时的首选
+			if inject.Primary {
+				buf.WriteString(fmt.Sprintf("container.Primary(\"%s\")\n", beanName))
+			}
+			// 依赖或配置变更时重新创建，接口类型的 bean 生成转发到当前实例的间接实现
+			if inject.Refresh {
+				buf.WriteString(fmt.Sprintf("container.Refreshable(\"%s\")\n", beanName))
+				if file, code, ok := refresh(node, returns[0]); ok {
+					refreshes[file] = code
+				}
+			}
+			// 集合注入中的顺序
+			if inject.Order != 0 {
+				buf.WriteString(fmt.Sprintf("container.Order(\"%s\", %d)\n", beanName, inject.Order))
+			}
+			// 创建实例的期限，超时后取消构造器的 context.Context 参数
+			if inject.Timeout != "" {
+				timeout, err := inject.TimeoutOf()
+				if err != nil {
+					proc.errs = append(proc.errs, fmt.Errorf("%w\n\t%s", err, location))
+					continue
+				}
+				buf.WriteString(fmt.Sprintf("container.ProvideTimeout(\"%s\", %d) // %s\n", iocClass, timeout, timeout))
+			}
+			// 实例化与初始化的先后关系
+			for _, order := range [][2]string{{"DependsOn", inject.DependsOn}, {"Before", inject.Before}, {"After", inject.After}} {
+				if values := quote(order[1]); len(values) > 0 {
+					buf.WriteString(fmt.Sprintf("container.%s(\"%s\", %s)\n", order[0], beanName, strings.Join(values, ", ")))
+				}
+			}
+			start := buf.Len()
+			if inject.Scope != "" {
+				// 作用域 bean 在子作用域中创建，参数 container 为所在的子作用域
+				buf.WriteString(fmt.Sprintf("if err := sdk.ProvideScoped(container, \"%s\", \"%s\", func(container *sdk.Container) (%s) {\n", inject.Scope, iocClass, results))
+			} else if inject.Assisted == "" {
+				buf.WriteString(fmt.Sprintf("if err := sdk.%s(container, \"%s\", func() (%s) {\n", Or(inject.Singleton, "ProvideBean", "ProvideTransient"), iocClass, results))
 			}
-			buf.WriteString(fmt.Sprintf("sdk.%s(container, \"%s\", func() (%s) {\n", Or(inject.Singleton, "ProvideBean", "ProvideTransient"), iocClass, results))
 			{
 				// 参数生成
-				var vars []string
+				var vars, params []string
+				values := inject.ValuesOf()
 				i := -1
 				args := convert.ExtractArguments(node.Lookup(), convert.node)
 				for _, argv := range args {
@@ -141,7 +255,7 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 						}
 
 						if argv.Interface.Alias() != "" {
-							if argv.Interface == "sdk.Container" {
+							if argv.Interface == "sdk.Container" || argv.Interface == "context.Context" {
 								goto argvLabel
 							}
 							ip, ok := lookup.FindImportByAlias(argv.Interface.Alias())
@@ -154,7 +268,57 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 						}
 					argvLabel:
 
-						iocClass = Or(argv.IsPointer, "*", "") + importPath + "." + argv.Interface.Ext()
+						// 辅助注入：由工厂的调用方传入
+						if assisted[i] {
+							params = append(params, n+" "+Or(argv.IsArray, "[]", "")+Or(argv.IsMap, "map[string]", "")+argv.String())
+							continue
+						}
+
+						// 创建该 bean 的上下文
+						if argv.Interface == "context.Context" {
+							buf.WriteString(fmt.Sprintf("	%s := sdk.ContextOf(container)\n", n))
+							continue
+						}
+
+						// 配置项注入
+						if value, ok := values[i]; ok {
+							typ := Or(argv.IsArray, "[]", "") + argv.String()
+							params := strings.Join(Map(OfSlice(value), strconv.Quote).ToSlice(), ", ")
+							requires = append(requires, fmt.Sprintf(`sdk.RequiresValue[%s](container, "%s", %s)`, typ, beanName, params))
+							buf.WriteString(fmt.Sprintf("	%s, err := sdk.Value[%s](container, %s)\n", n, typ, params))
+							buf.WriteString(fmt.Sprintf("	if err != nil {\n		var zero %s\n		return zero, err\n	}\n", returns[0].String()))
+							continue
+						}
+
+						// 集合注入：所有可赋值给元素类型的 bean
+						if argv.IsArray || argv.IsMap {
+							// 工厂在调用时获取集合，不参与构造顺序与循环依赖检查
+							if inject.Assisted == "" {
+								requires = append(requires, fmt.Sprintf(`sdk.RequiresAll[%s](container, "%s")`, argv.String(), beanName))
+							}
+							buf.WriteString(fmt.Sprintf("	%s, err := sdk.Invoke%s[%s](container)\n", n, Or(argv.IsArray, "Slice", "Map"), argv.String()))
+							buf.WriteString(fmt.Sprintf("	if err != nil {\n		var zero %s\n		return zero, err\n	}\n", returns[0].String()))
+							continue
+						}
+
+						// Provider/Lazy/Optional 按类型参数获取 bean
+						elem, wrapper := argv, ""
+						if w, t, ok := strings.Cut(argv.Interface.Ext(), "["); ok && importPath == "github.com/iocgo/sdk" && slices.Contains(wrappers, w) {
+							t = strings.TrimSuffix(t, "]")
+							wrapper = w
+							elem = Argv{Interface: Interface(strings.TrimPrefix(t, "*")), IsPointer: strings.HasPrefix(t, "*")}
+							importPath = convert.ImportPath()
+							if alias := elem.Interface.Alias(); alias != "" {
+								if ip, ok := lookup.FindImportByAlias(alias); ok {
+									importPath = ip
+									if ip != "github.com/iocgo/sdk" {
+										imports, _ = Import(imports, alias, ip)
+									}
+								}
+							}
+						}
+
+						iocClass = Or(elem.IsPointer, "*", "") + importPath + "." + elem.Interface.Ext()
 						if iocClass == "*github.com/iocgo/sdk.Container" {
 							if n != "container" {
 								buf.WriteString(fmt.Sprintf("%s := container\n", n))
@@ -206,16 +370,41 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 							}
 						}
 
-						buf.WriteString(fmt.Sprintf(`	%s, err := sdk.InvokeBean[%s](container, "%s")`, n, argv.String(), iocClass))
+						if inject.Assisted == "" && (wrapper == "" || wrapper == "Optional") {
+							graph[beanName] = append(graph[beanName], iocClass)
+						}
+						switch wrapper {
+						case "Provider", "Lazy":
+							requires = append(requires, fmt.Sprintf(`sdk.RequiresLazy[%s](container, "%s", "%s")`, elem.String(), beanName, iocClass))
+							buf.WriteString(fmt.Sprintf("	%s := sdk.New%s[%s](container, \"%s\")\n", n, wrapper, elem.String(), iocClass))
+							continue
+						case "Optional":
+							requires = append(requires, fmt.Sprintf(`sdk.RequiresOptional[%s](container, "%s", "%s")`, elem.String(), beanName, iocClass))
+							buf.WriteString(fmt.Sprintf(`	%s, err := sdk.InvokeOptional[%s](container, "%s")`, n, elem.String(), iocClass))
+						default:
+							// 工厂在调用时获取依赖，不参与构造顺序与循环依赖检查
+							requires = append(requires, fmt.Sprintf(`sdk.%s[%s](container, "%s", "%s")`, Or(inject.Assisted != "", "RequiresLazy", "Requires"), argv.String(), beanName, iocClass))
+							buf.WriteString(fmt.Sprintf(`	%s, err := sdk.InvokeBean[%s](container, "%s")`, n, argv.String(), iocClass))
+						}
 						buf.WriteString("\n")
 						buf.WriteString(fmt.Sprintf("	if err != nil {\n		var zero %s\n		return zero, err\n	}", returns[0].String()))
 						buf.WriteString("\n")
 					}
 				}
 				results = strings.Join(vars, ", ")
+
+				// 工厂的参数在生成依赖获取代码后才确定
+				if inject.Assisted != "" {
+					code := buf.String()
+					types, _ := joinReturn(returns)
+					buf.Reset()
+					buf.WriteString(code[:start])
+					buf.WriteString(fmt.Sprintf("if err := sdk.ProvideFactory(container, \"%s\", func(%s) (%s) {\n", product, strings.Join(params, ", "), types))
+					buf.WriteString(code[start:])
+				}
 			}
 
-			var1 := ""
+			var1, var2 := "", ""
 			str := strings.Join(FlatMap(OfSlice(returns), func(t Argv) []string {
 				return Map(OfSlice(t.Names), func(n string) string {
 					if n == "" || n == "_" {
@@ -224,26 +413,59 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 					}
 					if var1 == "" {
 						var1 = n
+					} else {
+						var2 = n
 					}
 					return n
 				}).ToSlice()
 			}).ToSlice(), ", ")
 
 			buf.WriteString(fmt.Sprintf("	%s := %s(%s)\n", str, convert.GetAstName(), results))
-			// 执行初始化方法
+			if !padding && inject.Destroy != "" {
+				buf.WriteString(fmt.Sprintf("	if %s != nil {\n		return %s\n	}\n", var2, str))
+			}
+			// 初始化方法在后置处理器的 BeforeInit 与 AfterInit 之间执行
 			if init := inject.Initialize; init != "" {
-				buf.WriteString("	// Invoke initialize method\n")
-				buf.WriteString(fmt.Sprintf("	%s.%s()\n", var1, init))
+				requires = append(requires, fmt.Sprintf("sdk.OnInit(container, \"%s\", func(bean %s) error { return sdk.Hook(bean.%s)(sdk.ContextOf(container)) })", beanName, returns[0].String(), init))
+			}
+			// 注册销毁方法，容器关闭时按创建的逆序执行
+			if destroy := inject.Destroy; destroy != "" {
+				buf.WriteString("	// Register destroy method\n")
+				buf.WriteString(fmt.Sprintf("	container.OnDestroy(\"%s\", sdk.Hook(%s.%s))\n", beanName, var1, destroy))
+			}
+			buf.WriteString(fmt.Sprintf("	return %s%s }); err != nil {\n\treturn err\n}", str, Or(padding, ", nil", "")))
+			// 声明构造器依赖与初始化方法
+			for _, require := range requires {
+				buf.WriteString("\n" + require)
+			}
+
+			// 条件注册
+			if len(conditions) > 0 {
+				if !inject.IsLazy {
+					buf.WriteString(fmt.Sprintf("\ncontainer.Eager(\"%s\")", beanName))
+				}
+				// 条件注册中的别名冲突在 Run 时返回
+				codes = append(codes, fmt.Sprintf("container.ConditionalErr(func() error {\n%s\nreturn nil\n}, %s)", buf.String(), strings.Join(conditions, ", ")))
+				continue
 			}
-			buf.WriteString(fmt.Sprintf("	return %s%s })", str, Or(padding, ", nil", "")))
 			codes = append(codes, buf.String())
 		}
 	}
 
+	// 循环依赖不生成代码，错误由 Err 返回
+	if err := cycles(graph, aliases, locations); err != nil {
+		proc.errs = append(proc.errs, err)
+		return
+	}
+
 	if len(activated) > 0 {
 		codes = append(codes, "\t// Initialized instance\n\t//")
 		codes = append(codes, activated...)
 	}
+	if len(decorates) > 0 {
+		codes = append(codes, "\t// Decorators\n\t//")
+		codes = append(codes, decorates...)
+	}
 
 	instance, err := template.New("ioc").Parse(iocTemplate)
 	if err != nil {
@@ -267,7 +489,78 @@ func Inject(proc *Processor) (ops map[string][]byte) {
 		panic(err)
 	}
 
-	ops[filepath.Join(tempDir, importPath, "container.gen.go")] = buf.Bytes()
+	ops[filepath.Join(tempDir, importPath, containerFile)] = buf.Bytes()
+	maps.Copy(ops, refreshes)
+	return
+}
+
+// 检查同一包内 bean 之间的循环依赖，跨包的依赖与未注册的依赖由 Container.Validate 在运行时校验
+func cycles(graph map[string][]string, aliases, locations map[string]string) error {
+	resolve := func(name string) string {
+		for range aliases {
+			n, ok := aliases[name]
+			if !ok {
+				break
+			}
+			name = n
+		}
+		return name
+	}
+
+	var (
+		errs  []error
+		chain []string
+		state = make(map[string]int)
+		visit func(name string)
+	)
+
+	visit = func(name string) {
+		chain = append(chain, name+"  in "+locations[name])
+		defer func() { chain = chain[:len(chain)-1] }()
+
+		switch state[name] {
+		case 1:
+			errs = append(errs, fmt.Errorf("circular dependency occurs:\n\t%s", strings.Join(chain, "\n\t")))
+			return
+		case 2:
+			return
+		}
+
+		state[name] = 1
+		for _, dep := range graph[name] {
+			if _, ok := locations[resolve(dep)]; ok {
+				visit(resolve(dep))
+			}
+		}
+		state[name] = 2
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(locations)) {
+		visit(name)
+	}
+	return errors.Join(errs...)
+}
+
+func conditionsOf(inject annotations.Inject) (conditions []string) {
+	if profiles := quote(inject.Profile); len(profiles) > 0 {
+		conditions = append(conditions, fmt.Sprintf("sdk.OnProfile(%s)", strings.Join(profiles, ", ")))
+	}
+	for _, property := range quote(inject.Property) {
+		conditions = append(conditions, fmt.Sprintf("sdk.OnProperty(%s)", property))
+	}
+	for _, missing := range quote(inject.Missing) {
+		conditions = append(conditions, fmt.Sprintf("sdk.OnMissingBean(%s)", missing))
+	}
+	return
+}
+
+// 按逗号拆分注解的值并转为字符串字面量
+func quote(value string) (values []string) {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, strconv.Quote(v))
+		}
+	}
 	return
 }
 
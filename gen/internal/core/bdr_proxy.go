@@ -196,6 +196,8 @@ func eachMethod(
 		line := fmt.Sprintf("//line %s:%d", filepath.Join(meta.Dir(), meta.FileName()), lookup.GetFSet().Position(method.Pos()).Line)
 		buf.WriteString(line + "\n")
 		buf.WriteString(fmt.Sprintf(`func (obj *_%s_px__) %s(%s) %s {`, strings.ReplaceAll(n, ".", "__"), method.Names[0].String(), args, returns))
+		// 调用次数与耗时统计
+		buf.WriteString(fmt.Sprintf("\n\tdefer proxy.Invoked(\"%s\", \"%s\")()", Or(strings.Contains(n, "."), n, meta.PackageName()+"."+n), method.Names[0].String()))
 		buf.WriteString(fmt.Sprintf(`
 					var __px_ctx_ = &proxy.Context{
 						Method:   "%s",
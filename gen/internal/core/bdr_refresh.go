@@ -0,0 +1,230 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	annotations "github.com/bincooo/go-annotation/pkg"
+	goMeta "github.com/iocgo/sdk/gen/internal/meta"
+)
+
+var rfTemplate = `package {{ .package }}
+
+import (
+	"github.com/iocgo/sdk/proxy"
+{{- range $import := .imports}}
+	{{$import.String}}
+{{- end}}
+)
+
+type _{{ .type }}_rf__ struct{ get func() ({{ .name }}, error) }
+
+func init() {
+	proxy.RegRefresh[{{ .name }}](func(get func() ({{ .name }}, error)) {{ .name }} {
+		return &_{{ .type }}_rf__{get}
+	})
+}
+{{ .code }}`
+
+// 生成 refresh bean 的间接实现，每个方法都转发到容器中的当前实例，
+// 获取当前实例失败时最后一个返回值为 error 的方法返回该错误，其余方法 panic。bean 的类型不是接口时返回 false
+func refresh(node annotations.Node, re Argv) (file string, code []byte, ok bool) {
+	if re.IsPointer || re.IsArray || re.IsMap {
+		return
+	}
+
+	meta := node.Meta()
+	dir, alias := meta.Dir(), re.Interface.Alias()
+	var imports []Imported
+	if alias != "" {
+		packageInfo, err := goMeta.FindPackageByImports(node.Imports(), alias)
+		if err != nil {
+			return
+		}
+		dir = packageInfo.Dir
+		imports = append(imports, Imported{alias, packageInfo.ImportPath})
+	}
+
+	typ := strings.ReplaceAll(re.Interface.String(), ".", "__")
+	var buf bytes.Buffer
+	if !forward(&buf, &imports, dir, re.Interface.Ext(), Imported{alias, ""}, typ) {
+		return
+	}
+
+	var out bytes.Buffer
+	instance := panicOnError(template.New(typ).Parse(rfTemplate))
+	if err := instance.Execute(&out, map[string]any{
+		"package": meta.PackageName(),
+		"imports": imports,
+		"name":    re.Interface.String(),
+		"type":    typ,
+		"code":    buf.String(),
+	}); err != nil {
+		panic(err)
+	}
+
+	_, importPath, err := commandAsImportPath(rootPath)
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(tempDir, importPath, ToSnakeCase(typ)+"_rf.gen.go"), out.Bytes(), true
+}
+
+// 生成接口 name 的方法转发，嵌入的接口递归展开。pkg 为接口所在包在生成代码中的导入，同一包时为空
+func forward(buf *bytes.Buffer, imports *[]Imported, dir, name string, pkg Imported, typ string) bool {
+	spec, file := interfaceSpec(dir, name)
+	if spec == nil || spec.TypeParams != nil {
+		return false
+	}
+
+	use := func(ip Imported) {
+		if ip.ImportPath != "" && !slices.ContainsFunc(*imports, func(i Imported) bool { return i.ImportPath == ip.ImportPath }) {
+			*imports = append(*imports, ip)
+		}
+	}
+
+	// 接口方法中引用的包，以及所在包的导出类型
+	qualify := func(expr ast.Expr) string {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			switch ex := n.(type) {
+			case *ast.SelectorExpr:
+				if x, ok := ex.X.(*ast.Ident); ok {
+					if ip, has := importOf(file, x.Name); has {
+						use(Imported{x.Name, ip})
+					}
+				}
+				return false
+			case *ast.Ident:
+				if pkg.Alias != "" && ex.IsExported() {
+					ex.Name = pkg.Alias + "." + ex.Name
+					use(pkg)
+				}
+			}
+			return true
+		})
+		return types.ExprString(expr)
+	}
+
+	for _, method := range spec.Type.(*ast.InterfaceType).Methods.List {
+		ft, ok := method.Type.(*ast.FuncType)
+		if !ok {
+			// 嵌入的接口
+			switch ex := method.Type.(type) {
+			case *ast.Ident:
+				ok = forward(buf, imports, dir, ex.Name, pkg, typ)
+			case *ast.SelectorExpr:
+				x, _ := ex.X.(*ast.Ident)
+				if x == nil {
+					return false
+				}
+				packageInfo, err := goMeta.FindPackageByImports(file.Imports, x.Name)
+				if err != nil {
+					return false
+				}
+				ok = forward(buf, imports, packageInfo.Dir, ex.Sel.Name, Imported{x.Name, packageInfo.ImportPath}, typ)
+			}
+			if !ok {
+				return false
+			}
+			continue
+		}
+
+		n := method.Names[0].Name
+		if pkg.Alias != "" && !ast.IsExported(n) {
+			return false
+		}
+
+		pos := 1
+		var params, args, results []string
+		for _, field := range ft.Params.List {
+			_, variadic := field.Type.(*ast.Ellipsis)
+			t := qualify(field.Type)
+			for range max(len(field.Names), 1) {
+				v := "var" + strconv.Itoa(pos)
+				pos++
+				params = append(params, v+" "+t)
+				args = append(args, v+Or(variadic, "...", ""))
+			}
+		}
+		if ft.Results != nil {
+			for _, field := range ft.Results.List {
+				t := qualify(field.Type)
+				for range max(len(field.Names), 1) {
+					results = append(results, t)
+				}
+			}
+		}
+
+		// 最后一个返回值为 error 时使用命名返回值，获取失败时返回零值与错误
+		fails := "panic(err)"
+		if l := len(results); l > 0 && results[l-1] == "error" {
+			for i := range results[:l-1] {
+				results[i] = "ret" + strconv.Itoa(i+1) + " " + results[i]
+			}
+			results[l-1] = "err error"
+			fails = "return"
+		}
+		returns := strings.Join(results, ", ")
+		if len(results) > 1 || fails == "return" {
+			returns = "(" + returns + ")"
+		}
+		buf.WriteString(fmt.Sprintf("\nfunc (obj *_%s_rf__) %s(%s) %s {\n\tcurrent, err := obj.get()\n\tif err != nil {\n\t\t%s\n\t}\n\t%scurrent.%s(%s)\n}\n",
+			typ, n, strings.Join(params, ", "), returns, fails, Or(len(results) > 0, "return ", ""), n, strings.Join(args, ", ")))
+	}
+	return true
+}
+
+// 在包目录中查找名为 name 的接口声明及其所在的文件
+func interfaceSpec(dir, name string) (*ast.TypeSpec, *ast.File) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		f, pErr := parser.ParseFile(fset, filepath.Join(dir, entry.Name()), nil, parser.SkipObjectResolution)
+		if pErr != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				if spec := s.(*ast.TypeSpec); spec.Name.Name == name {
+					if _, ok = spec.Type.(*ast.InterfaceType); ok {
+						return spec, f
+					}
+					return nil, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// 文件中 alias 对应的导入路径
+func importOf(file *ast.File, alias string) (string, bool) {
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil && imp.Name.Name == alias || imp.Name == nil && path[strings.LastIndex(path, "/")+1:] == alias {
+			return path, true
+		}
+	}
+	return "", false
+}
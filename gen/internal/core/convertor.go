@@ -30,6 +30,8 @@ type Argv struct {
 	Interface  Interface
 	IsPointer  bool
 	IsArray    bool
+	// map[string]T
+	IsMap bool
 }
 
 func (i Interface) Alias() string {
@@ -144,9 +146,18 @@ func (convert *Convertor) extractArgs(lookup annotation.Lookup, results *ast.Fie
 	}
 
 	for _, re := range results.List {
-		var isPointer, isArray bool
+		var isPointer, isArray, isMap bool
 		var interfaceName string
 
+		// 集合的元素类型
+		elem := func(expr ast.Expr) string {
+			if star, ok := expr.(*ast.StarExpr); ok {
+				isPointer = true
+				expr = star.X
+			}
+			return convert.parseInterfaceName(expr)
+		}
+
 		switch expr := re.Type.(type) {
 		case *ast.StarExpr:
 			isPointer = true
@@ -155,7 +166,15 @@ func (convert *Convertor) extractArgs(lookup annotation.Lookup, results *ast.Fie
 			interfaceName = convert.parseInterfaceName(expr)
 		case *ast.ArrayType:
 			isArray = true
-			interfaceName = convert.parseInterfaceName(expr.Elt)
+			interfaceName = elem(expr.Elt)
+		case *ast.MapType:
+			if key, ok := expr.Key.(*ast.Ident); !ok || key.Name != "string" {
+				panic("the map key type must be string")
+			}
+			isMap = true
+			interfaceName = elem(expr.Value)
+		case *ast.IndexExpr:
+			interfaceName = convert.parseInterfaceName(expr)
 		default:
 			interfaceName = re.Type.(*ast.Ident).Name
 		}
@@ -181,6 +200,7 @@ func (convert *Convertor) extractArgs(lookup annotation.Lookup, results *ast.Fie
 			Names:      names,
 			IsPointer:  isPointer,
 			IsArray:    isArray,
+			IsMap:      isMap,
 		})
 	}
 	return args
@@ -234,6 +254,8 @@ func parseT(expr *ast.IndexExpr) string {
 	case *ast.SelectorExpr:
 		genericParams = append(genericParams,
 			fmt.Sprintf("%s.%s", param.X.(*ast.Ident).Name, param.Sel.Name))
+	case *ast.StarExpr:
+		genericParams = append(genericParams, "*"+parseT(&ast.IndexExpr{Index: param.X}))
 	case *ast.InterfaceType:
 		genericParams = append(genericParams, "interface{}")
 	}
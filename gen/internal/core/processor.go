@@ -20,6 +20,8 @@ type Builder func(proc *Processor) map[string][]byte
 type Processor struct {
 	builders map[string]Builder
 	mapping  map[annotation.Node][]Convertor
+	// 生成代码时发现的错误
+	errs []error
 }
 
 var _ annotation.AnnotationProcessor = (*Processor)(nil)
@@ -43,6 +45,8 @@ func init() {
 	annotation.Register[annotations.Proxy](proc)
 	annotation.Register[annotations.Inject](proc)
 	annotation.Register[annotations.Router](proc)
+	annotation.Register[annotations.Config](proc)
+	annotation.Register[annotations.Decorate](proc)
 
 }
 
@@ -55,6 +59,11 @@ func Root(root, _tempDir string) {
 	tempDir = _tempDir
 }
 
+// Err 返回生成代码时发现的错误，如同一包内 bean 之间的循环依赖
+func Err() error {
+	return errors.Join(proc.errs...)
+}
+
 func (proc *Processor) Version() string {
 	return "v1.0.0"
 }
@@ -63,15 +72,19 @@ func (proc *Processor) Name() string {
 	return "iocgo"
 }
 
+// 生成 container.gen.go 的 builder 的键，@Inject、@Config 与 @Decorate 共用
+const containerFile = "container.gen.go"
+
 func (proc *Processor) Process(node annotation.Node) error {
+	meta := node.Meta()
+	target := filepath.Join(meta.Dir(), meta.FileName())
 	return errors.Join(
-		scanAnnotated[annotations.Gen](proc, node, func(t annotations.Gen) Builder {
-			meta := node.Meta()
-			return Wire(filepath.Join(meta.Dir(), meta.FileName()))
-		}),
-		scanAnnotated[annotations.Inject](proc, node, func(tag annotations.Inject) Builder { return Inject }),
-		scanAnnotated[annotations.Router](proc, node, func(tag annotations.Router) Builder { return Router }),
-		scanAnnotated[annotations.Proxy](proc, node, func(tag annotations.Proxy) Builder { return Proxy }),
+		scanAnnotated[annotations.Gen](proc, node, target, func(t annotations.Gen) Builder { return Wire(target) }),
+		scanAnnotated[annotations.Inject](proc, node, containerFile, func(tag annotations.Inject) Builder { return Inject }),
+		scanAnnotated[annotations.Router](proc, node, annotations.Router{}.Name(), func(tag annotations.Router) Builder { return Router }),
+		scanAnnotated[annotations.Proxy](proc, node, annotations.Proxy{}.Name(), func(tag annotations.Proxy) Builder { return Proxy }),
+		scanAnnotated[annotations.Config](proc, node, containerFile, func(tag annotations.Config) Builder { return Inject }),
+		scanAnnotated[annotations.Decorate](proc, node, containerFile, func(tag annotations.Decorate) Builder { return Inject }),
 	)
 }
 
@@ -85,7 +98,8 @@ func (proc *Processor) Output() (ops map[string][]byte) {
 	return
 }
 
-func scanAnnotated[T annotations.M](proc *Processor, node annotation.Node, then func(t T) Builder) (err error) {
+// 扫描节点上的注解 T，key 为 then 返回的 builder 的键，生成同一文件的注解使用相同的键共用一个 builder
+func scanAnnotated[T annotations.M](proc *Processor, node annotation.Node, key string, then func(t T) Builder) (err error) {
 	meta := node.Meta()
 	if meta.Dir() != rootPath {
 		return
@@ -131,9 +145,9 @@ func scanAnnotated[T annotations.M](proc *Processor, node annotation.Node, then
 	convertor := newConvertor(zero, goAst, importPath.ImportPath)
 	proc.mapping[node] = append(proc.mapping[node], convertor)
 	if then != nil {
-		if _, ok = proc.builders[zero.Name()]; !ok {
+		if _, ok = proc.builders[key]; !ok {
 			if builder := then(zero); builder != nil {
-				proc.builders[zero.Name()] = builder
+				proc.builders[key] = builder
 			}
 		}
 	}
@@ -67,7 +67,9 @@ func compile(args []string) (nArgs []string, err error) {
 		logLv = "f"
 	}
 	// 中间代码生成
-	gen.Process(packageDir, tempDir, logLv)
+	if err = gen.Process(packageDir, tempDir, logLv); err != nil {
+		return
+	}
 
 	joinPath := filepath.Join(tempDir, packageIn.ImportPath)
 	if !meta.IsExist(joinPath) {
@@ -15,6 +15,17 @@ var (
 )
 
 func Process() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "graph":
+			graph(os.Args[2:])
+			return
+		case "validate":
+			validate(os.Args[2:])
+			return
+		}
+	}
+
 	initUseFlag()
 	initTempDir()
 
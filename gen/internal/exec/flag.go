@@ -40,7 +40,9 @@ func initUseFlag() {
 	flag.Usage = func() {
 		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
 		_, _ = fmt.Fprintf(flag.CommandLine.Output(),
-			"gen [-d.log] [-d.tempDir] chainToolPath chainArgs\n")
+			"gen [-d.log] [-d.tempDir] chainToolPath chainArgs\n"+
+				"gen graph [-format] [package] [args]\n"+
+				"gen validate [package] [args]\n")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
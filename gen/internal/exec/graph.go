@@ -0,0 +1,52 @@
+package exec
+
+import (
+	"flag"
+	"fmt"
+	"github.com/iocgo/sdk/gen/internal/logger"
+	"os"
+	"os/exec"
+)
+
+// 以 iocgo 编译并运行目标程序，由程序中的 Container.Inspect 输出依赖关系图
+func graph(args []string) {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := flags.String("format",
+		"dot",
+		"output format. dot/mermaid/json")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage of %s graph:\n", os.Args[0])
+		_, _ = fmt.Fprintf(flags.Output(),
+			"iocgo graph [-format] [package] [args]\n")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	pkg := "."
+	if flags.NArg() > 0 {
+		pkg = flags.Arg(0)
+	}
+
+	var runArgs []string
+	if flags.NArg() > 1 {
+		runArgs = flags.Args()[1:]
+	}
+	goRun("graph", pkg, runArgs, "IOCGO_GRAPH="+*format)
+}
+
+// 以 iocgo 作为 toolexec 编译并运行目标程序
+func goRun(name, pkg string, args []string, env string) {
+	toolPath, err := os.Executable()
+	if err != nil {
+		logger.Error(name+": executable path not found", err)
+	}
+
+	cmd := exec.Command("go", append([]string{"run", "-toolexec", toolPath, pkg}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), env)
+	if err = cmd.Run(); err != nil {
+		logger.Error(name+": run", pkg, err)
+	}
+}
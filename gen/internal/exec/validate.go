@@ -0,0 +1,30 @@
+package exec
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// 以 iocgo 编译并运行目标程序，由程序中的 Container.Inspect 校验所有 bean 的依赖，不会实例化任何 bean
+func validate(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage of %s validate:\n", os.Args[0])
+		_, _ = fmt.Fprintf(flags.Output(),
+			"iocgo validate [package] [args]\n")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	pkg := "."
+	if flags.NArg() > 0 {
+		pkg = flags.Arg(0)
+	}
+
+	var runArgs []string
+	if flags.NArg() > 1 {
+		runArgs = flags.Args()[1:]
+	}
+	goRun("validate", pkg, runArgs, "IOCGO_VALIDATE=1")
+}
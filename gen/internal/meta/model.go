@@ -169,6 +169,11 @@ func GetInterfaceInfo(dirOrFile, name string) (spec *ast.TypeSpec, err error) {
 
 	if spec == nil {
 		err = fmt.Errorf("could not find interface info for %s", name)
+		return
+	}
+	if _, ok := spec.Type.(*ast.InterfaceType); !ok {
+		err = fmt.Errorf("the type %s is not interface", name)
+		return
 	}
 
 	err = eachMethod(imports, spec.Type.(*ast.InterfaceType).Methods)
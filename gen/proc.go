@@ -10,7 +10,8 @@ func Alias[T gen.M]() {
 	core.Alias[T]()
 }
 
-func Process(root, tempDir, logLv string) {
+func Process(root, tempDir, logLv string) error {
 	core.Root(root, tempDir)
 	annotation.Process(root, logLv)
+	return core.Err()
 }
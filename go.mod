@@ -5,6 +5,7 @@ go 1.23.3
 require (
 	github.com/bincooo/go-annotation v0.0.0-20241108042945-27c9c686ed28
 	github.com/gin-gonic/gin v1.10.0
+	github.com/mitchellh/mapstructure v1.5.0
 	github.com/samber/do/v2 v2.0.0-beta.7
 	github.com/spf13/cobra v1.8.1
 	github.com/spf13/pflag v1.0.5
@@ -37,7 +38,6 @@ require (
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pelletier/go-toml/v2 v2.2.2 // indirect
@@ -0,0 +1,168 @@
+package sdk
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iocgo/sdk/runtime"
+)
+
+// 正在创建的单例 bean 及创建它的协程，用于检查跨协程的循环依赖
+var building sync.Map
+
+// 请求其它协程正在创建的 bean 时，等待创建完成的期限，超过后才解析所有协程的调用栈
+const crossCycleWait = 50 * time.Millisecond
+
+type buildKey struct {
+	container *Container
+	name      string
+}
+
+type build struct {
+	owner *keys
+	// 创建结束时关闭
+	done chan struct{}
+}
+
+// 记录当前协程开始创建 bean，返回结束方法。未经 invoke 直接通过 do 创建时不记录
+func (c *Container) constructing(name string) func() {
+	if !threadLocal.Ex(false) {
+		return func() {}
+	}
+	if b, ok := c.meta(name); ok && b.transient {
+		return func() {}
+	}
+
+	key, b := buildKey{c, name}, &build{threadLocal.Load(), make(chan struct{})}
+	building.Store(key, b)
+	return func() {
+		building.CompareAndDelete(key, b)
+		close(b.done)
+	}
+}
+
+// 当前协程请求的 bean 正由其它协程创建，且当前协程由该协程在创建这个 bean 期间（直接或间接）派生时，
+// 创建方等待当前协程而当前协程等待创建方，构成循环依赖。
+// 当前协程不由创建方直接派生时先等待创建完成，超过 crossCycleWait 仍未完成才解析所有协程的调用栈
+func (c *Container) crossCycle(value *keys, name string) *ErrCycle {
+	v, ok := building.Load(buildKey{c, name})
+	if !ok || v.(*build).owner == value {
+		return nil
+	}
+	b := v.(*build)
+	owner := b.owner
+
+	// 仅解析当前协程的调用栈即可得到其父协程，并发获取同一 bean 的协程通常在等待期间即可拿到实例
+	if g := runtime.Stacks(false)[value.id]; g == nil || g.Parent != owner.id {
+		select {
+		case <-b.done:
+			return nil
+		case <-time.After(crossCycleWait):
+		}
+	}
+
+	stacks := runtime.Stacks(true)
+	// 从当前协程沿父协程查找创建方
+	path := []int64{value.id}
+	for g := stacks[value.id]; g != nil && g.Parent != owner.id; g = stacks[g.Parent] {
+		if g.Parent < 0 || slices.Contains(path, g.Parent) {
+			return nil
+		}
+		path = append(path, g.Parent)
+	}
+	spawned := stacks[path[len(path)-1]]
+	if spawned == nil || stacks[owner.id] == nil {
+		return nil
+	}
+
+	// 派生协程的函数须位于创建方创建该 bean 的调用之内
+	chain := owner.snapshot()
+	idx := slices.Index(chain, name)
+	frames := stacks[owner.id].Frames
+	var provides []int
+	for i, frame := range frames {
+		if strings.HasPrefix(frame.Function, "github.com/iocgo/sdk.provide[") {
+			provides = append(provides, i)
+		}
+	}
+	if idx < 0 || idx >= len(provides) {
+		return nil
+	}
+	slices.Reverse(provides)
+	if !slices.ContainsFunc(frames[:provides[idx]], func(frame runtime.Frame) bool { return frame.Function == spawned.Creator }) {
+		return nil
+	}
+
+	locations := locationsOf(frames, len(chain))
+	// 由外向内拼接路径上每个协程的依赖链
+	for _, id := range slices.Backward(path) {
+		k, has := threadLocal.Lookup(id)
+		if !has {
+			continue
+		}
+		g := k.snapshot()
+		chain = append(chain, g...)
+		locations = append(locations, locationsOf(stacks[id].Frames, len(g))...)
+	}
+	return &ErrCycle{Name: name, Chain: chain, Locations: c.located(chain, locations)}
+}
+
+// 依赖链上每个 bean 被请求的位置，从当前协程的调用栈中解析，仅用于错误信息
+func (k *keys) locations(n int) []string {
+	if g := runtime.Stacks(false)[k.id]; g != nil {
+		return locationsOf(g.Frames, n)
+	}
+	return make([]string, n)
+}
+
+// 每个 invoke 调用外最近的 sdk 与 do 之外的调用位置即请求 bean 的位置，由内向外与依赖链的末尾对齐
+func locationsOf(frames []runtime.Frame, n int) []string {
+	var (
+		found   []string
+		pending int
+		invoked bool
+	)
+	for _, frame := range frames {
+		switch {
+		case strings.HasPrefix(frame.Function, "github.com/iocgo/sdk.invoke["):
+			// 子作用域回退到父容器时连续的 invoke 只对应一次请求
+			if !invoked {
+				pending++
+			}
+			invoked = true
+			continue
+		case strings.HasPrefix(frame.Function, "github.com/iocgo/sdk."),
+			strings.HasPrefix(frame.Function, "github.com/iocgo/sdk/runtime."),
+			strings.HasPrefix(frame.Function, "github.com/samber/do/"):
+		default:
+			// 生成代码中的请求位置由 located 补全为构造器的注册位置
+			location := fmt.Sprintf("%s:%d", frame.File, frame.Line)
+			if generated(frame.File) {
+				location = ""
+			}
+			for ; pending > 0; pending-- {
+				found = append(found, location)
+			}
+		}
+		invoked = false
+	}
+
+	locations := make([]string, n)
+	for i := 0; i < n && i < len(found); i++ {
+		locations[n-1-i] = found[i]
+	}
+	return locations
+}
+
+// 补全生成代码中的请求位置：第i个bean由第i-1个bean的构造器请求
+func (c *Container) located(chain, locations []string) []string {
+	for i := 1; i < len(locations) && i < len(chain); i++ {
+		if locations[i] == "" {
+			locations[i] = c.locationOf(chain[i-1])
+		}
+	}
+	return locations
+}
@@ -0,0 +1,247 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/iocgo/sdk/proxy"
+)
+
+// Graph bean 依赖关系图
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Transient bool     `json:"transient"`
+	Eager     bool     `json:"eager"`
+	Primary   bool     `json:"primary,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Proxies   []string `json:"proxies,omitempty"`
+	Module    string   `json:"module,omitempty"`
+	// 被依赖但未注册的 bean
+	Missing bool `json:"missing,omitempty"`
+}
+
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph 导出容器中的 bean 依赖关系图，不会实例化任何 bean
+func (c *Container) Graph() (g Graph) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	aliases := make(map[string][]string)
+	for _, n := range c.aliasNames() {
+		name := c.resolve(n)
+		aliases[name] = append(aliases[name], n)
+	}
+
+	interfaces := proxy.Interfaces()
+	for _, b := range c.beans {
+		node := GraphNode{
+			Name:      b.name,
+			Aliases:   aliases[b.name],
+			Transient: b.transient,
+			Eager:     b.eager,
+			Primary:   b.primary,
+			Scope:     b.scope,
+			Module:    b.module,
+			Missing:   b.typ == nil,
+		}
+
+		if b.typ != nil {
+			node.Type = b.typ.String()
+			for _, t := range interfaces {
+				if b.typ.Implements(t) {
+					node.Proxies = append(node.Proxies, t.String())
+				}
+			}
+		}
+
+		slices.Sort(node.Aliases)
+		slices.Sort(node.Proxies)
+		g.Nodes = append(g.Nodes, node)
+
+		for _, dep := range c.dependencies(b) {
+			// 未注册的可选依赖
+			if d, ok := c.beans[c.resolve(dep.name)]; dep.optional && (!ok || d.typ == nil) {
+				continue
+			}
+			g.Edges = append(g.Edges, GraphEdge{b.name, c.resolve(dep.name)})
+		}
+	}
+
+	// 被依赖但未注册的 bean
+	for _, edge := range g.Edges {
+		if _, ok := c.beans[edge.To]; !ok && !slices.ContainsFunc(g.Nodes, func(n GraphNode) bool { return n.Name == edge.To }) {
+			g.Nodes = append(g.Nodes, GraphNode{Name: edge.To, Missing: true})
+		}
+	}
+
+	slices.SortFunc(g.Nodes, func(a, b GraphNode) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(g.Edges, func(a, b GraphEdge) int {
+		return elseOf(a.From == b.From, strings.Compare(a.To, b.To), strings.Compare(a.From, b.From))
+	})
+	return
+}
+
+// DOT 输出 Graphviz 格式
+func (g Graph) DOT() string {
+	var buf strings.Builder
+	buf.WriteString("digraph iocgo {\n")
+	buf.WriteString("\trankdir=LR;\n")
+	buf.WriteString("\tnode [shape=box];\n")
+	g.eachModule(func(module string, nodes []GraphNode) {
+		indent := "\t"
+		if module != "" {
+			indent = "\t\t"
+			buf.WriteString(fmt.Sprintf("\tsubgraph %q {\n\t\tlabel=%q;\n", "cluster_"+module, module))
+		}
+		for _, node := range nodes {
+			attrs := []string{fmt.Sprintf("label=%q", node.label("\n"))}
+			if node.Transient {
+				attrs = append(attrs, `style="dashed"`)
+			}
+			if node.Eager {
+				attrs = append(attrs, `peripheries=2`)
+			}
+			if node.Missing {
+				attrs = append(attrs, `color="red"`)
+			}
+			buf.WriteString(fmt.Sprintf("%s%q [%s];\n", indent, node.Name, strings.Join(attrs, ", ")))
+		}
+		if module != "" {
+			buf.WriteString("\t}\n")
+		}
+	})
+	for _, edge := range g.Edges {
+		buf.WriteString(fmt.Sprintf("\t%q -> %q;\n", edge.From, edge.To))
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Mermaid 输出 Mermaid flowchart 格式
+func (g Graph) Mermaid() string {
+	ids := make(map[string]string)
+	var buf strings.Builder
+	buf.WriteString("flowchart LR\n")
+	g.eachModule(func(module string, nodes []GraphNode) {
+		indent := "\t"
+		if module != "" {
+			indent = "\t\t"
+			buf.WriteString(fmt.Sprintf("\tsubgraph m%d[\"%s\"]\n", len(ids), strings.ReplaceAll(module, `"`, "#quot;")))
+		}
+		for _, node := range nodes {
+			id := fmt.Sprintf("n%d", len(ids))
+			ids[node.Name] = id
+			label := strings.ReplaceAll(node.label("<br/>"), `"`, "#quot;")
+			switch {
+			case node.Missing:
+				buf.WriteString(fmt.Sprintf("%s%s{{\"%s\"}}\n", indent, id, label))
+			case node.Transient:
+				buf.WriteString(fmt.Sprintf("%s%s([\"%s\"])\n", indent, id, label))
+			default:
+				buf.WriteString(fmt.Sprintf("%s%s[\"%s\"]\n", indent, id, label))
+			}
+		}
+		if module != "" {
+			buf.WriteString("\tend\n")
+		}
+	})
+	for _, edge := range g.Edges {
+		buf.WriteString(fmt.Sprintf("\t%s --> %s\n", ids[edge.From], ids[edge.To]))
+	}
+	return buf.String()
+}
+
+// JSON 输出 JSON 格式
+func (g Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// WriteGraph 按格式输出依赖关系图，条件注册与 Validate 一样在元信息的副本上预演，
+// 除读取条件时临时创建尚未创建的配置源外，不会注册或创建 bean
+func (c *Container) WriteGraph(w io.Writer, format string) error {
+	defer c.rehearse()()
+	cErr := c.applyConditionals()
+	return errors.Join(cErr, c.Graph().WriteTo(w, format))
+}
+
+// WriteTo 按格式输出，支持 dot、mermaid、json
+func (g Graph) WriteTo(w io.Writer, format string) (err error) {
+	var data []byte
+	switch strings.ToLower(format) {
+	case "dot", "":
+		data = []byte(g.DOT())
+	case "mermaid":
+		data = []byte(g.Mermaid())
+	case "json":
+		if data, err = g.JSON(); err != nil {
+			return
+		}
+	default:
+		return fmt.Errorf("unsupported graph format: %s", format)
+	}
+	_, err = w.Write(data)
+	return
+}
+
+// 按模块分组遍历节点，不属于模块的节点在前，模块按名称排序
+func (g Graph) eachModule(f func(module string, nodes []GraphNode)) {
+	groups := make(map[string][]GraphNode)
+	var modules []string
+	for _, node := range g.Nodes {
+		if _, ok := groups[node.Module]; !ok && node.Module != "" {
+			modules = append(modules, node.Module)
+		}
+		groups[node.Module] = append(groups[node.Module], node)
+	}
+	slices.Sort(modules)
+
+	if nodes, ok := groups[""]; ok {
+		f("", nodes)
+	}
+	for _, module := range modules {
+		f(module, groups[module])
+	}
+}
+
+func (node GraphNode) label(sep string) string {
+	lines := []string{node.Name}
+	if len(node.Aliases) > 0 {
+		lines = append(lines, "alias: "+strings.Join(node.Aliases, ", "))
+	}
+
+	var flags []string
+	switch {
+	case node.Missing:
+		flags = append(flags, "missing")
+	case node.Transient:
+		flags = append(flags, "transient")
+	case node.Scope != "":
+		flags = append(flags, "scope: "+node.Scope)
+	default:
+		flags = append(flags, "singleton", elseOf(node.Eager, "eager", "lazy"))
+	}
+	if node.Primary {
+		flags = append(flags, "primary")
+	}
+	lines = append(lines, strings.Join(flags, ", "))
+
+	if len(node.Proxies) > 0 {
+		lines = append(lines, "proxy: "+strings.Join(node.Proxies, ", "))
+	}
+	return strings.Join(lines, sep)
+}
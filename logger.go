@@ -0,0 +1,32 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+)
+
+// 默认不输出日志
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// SetLogger 设置容器生命周期的日志，默认不输出。单个 bean 的创建、代理与关闭为 Debug 级别，
+// 初始化器顺序、启动与关闭结果为 Info 级别；子作用域沿用父容器的日志，并附加 scope 属性
+func (c *Container) SetLogger(logger *slog.Logger) {
+	c.logger = elseOf(logger == nil, discardLogger, logger)
+}
+
+// Logger 获取容器的日志
+func (c *Container) Logger() *slog.Logger {
+	return c.logger
+}
+
+// 根容器的启动与关闭为 Info 级别，子作用域随请求频繁开关，降为 Debug 级别
+func (c *Container) lifecycleLevel() slog.Level {
+	return elseOf(c.parent == nil, slog.LevelInfo, slog.LevelDebug)
+}
@@ -0,0 +1,191 @@
+package sdk
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/iocgo/sdk/proxy"
+)
+
+// DefaultBuckets 耗时直方图的默认分桶上界（秒），与 Prometheus 客户端一致
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram 耗时直方图，Counts 为小于等于对应上界的累计数量
+type Histogram struct {
+	Buckets []float64
+	Counts  []uint64
+	Count   uint64
+	Sum     float64
+}
+
+// BeanMetrics bean 的构造统计，Duration 包括失败的构造
+type BeanMetrics struct {
+	Name        string
+	Constructed uint64
+	Failed      uint64
+	Duration    Histogram
+}
+
+// MethodMetrics 代理方法的调用统计，Duration.Count 即调用次数
+type MethodMetrics struct {
+	Interface string
+	Method    string
+	Duration  Histogram
+}
+
+// Metrics 容器的统计快照，按名称排序
+type Metrics struct {
+	Beans   []BeanMetrics
+	Methods []MethodMetrics
+}
+
+// 耗时直方图，使用原子计数，并发观察时无需加锁
+type histogram struct {
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	// 纳秒
+	sum atomic.Int64
+}
+
+func newHistogram() *histogram {
+	return &histogram{counts: make([]atomic.Uint64, len(DefaultBuckets))}
+}
+
+func (h *histogram) observe(elapsed time.Duration) {
+	// 先计总数再计分桶，快照先读分桶再读总数，保证分桶的累计数量不超过总数
+	h.count.Add(1)
+	h.sum.Add(int64(elapsed))
+	if i, _ := slices.BinarySearch(DefaultBuckets, elapsed.Seconds()); i < len(h.counts) {
+		h.counts[i].Add(1)
+	}
+}
+
+func (h *histogram) snapshot() Histogram {
+	re := Histogram{Buckets: slices.Clone(DefaultBuckets), Counts: make([]uint64, len(DefaultBuckets))}
+	var total uint64
+	for i := range re.Counts {
+		if i < len(h.counts) {
+			total += h.counts[i].Load()
+		}
+		re.Counts[i] = total
+	}
+	re.Count = h.count.Load()
+	re.Sum = time.Duration(h.sum.Load()).Seconds()
+	return re
+}
+
+type beanStats struct {
+	constructed, failed uint64
+	duration            *histogram
+}
+
+// 容器的 bean 构造统计，子作用域记录到根容器
+type metrics struct {
+	mu    sync.Mutex
+	beans map[string]*beanStats
+}
+
+func (m *metrics) observe(name string, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats, ok := m.beans[name]
+	if !ok {
+		stats = &beanStats{duration: newHistogram()}
+		m.beans[name] = stats
+	}
+	if err != nil {
+		stats.failed++
+	} else {
+		stats.constructed++
+	}
+	stats.duration.observe(elapsed)
+}
+
+// 代理方法的调用统计，生成的代理不属于某个容器，所有容器共享。
+// 键为 [接口, 方法]，值为 *histogram，代理方法的调用之间不加锁
+var methods sync.Map
+
+func init() {
+	proxy.Observe(func(iface, method string, elapsed time.Duration) {
+		key := [2]string{iface, method}
+		h, ok := methods.Load(key)
+		if !ok {
+			h, _ = methods.LoadOrStore(key, newHistogram())
+		}
+		h.(*histogram).observe(elapsed)
+	})
+}
+
+// Metrics 获取 bean 构造（包括子作用域中的）与代理方法调用的统计，代理方法的调用统计在进程内所有容器间共享
+func (c *Container) Metrics() (re Metrics) {
+	c.metrics.mu.Lock()
+	for name, stats := range c.metrics.beans {
+		re.Beans = append(re.Beans, BeanMetrics{name, stats.constructed, stats.failed, stats.duration.snapshot()})
+	}
+	c.metrics.mu.Unlock()
+	slices.SortFunc(re.Beans, func(a, b BeanMetrics) int { return cmp.Compare(a.Name, b.Name) })
+
+	methods.Range(func(key, h any) bool {
+		k := key.([2]string)
+		re.Methods = append(re.Methods, MethodMetrics{k[0], k[1], h.(*histogram).snapshot()})
+		return true
+	})
+	slices.SortFunc(re.Methods, func(a, b MethodMetrics) int {
+		return cmp.Or(cmp.Compare(a.Interface, b.Interface), cmp.Compare(a.Method, b.Method))
+	})
+	return
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus 以 Prometheus 文本格式输出统计
+func (m Metrics) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	header := func(name, typ, help string) {
+		_, _ = fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	histogram := func(name, labels string, h Histogram) {
+		for i, bound := range h.Buckets {
+			_, _ = fmt.Fprintf(bw, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(bound), h.Counts[i])
+		}
+		_, _ = fmt.Fprintf(bw, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.Count)
+		_, _ = fmt.Fprintf(bw, "%s_sum{%s} %s\n", name, labels, formatFloat(h.Sum))
+		_, _ = fmt.Fprintf(bw, "%s_count{%s} %d\n", name, labels, h.Count)
+	}
+
+	if len(m.Beans) > 0 {
+		header("iocgo_bean_constructions_total", "counter", "Number of successful bean constructions.")
+		for _, b := range m.Beans {
+			_, _ = fmt.Fprintf(bw, "iocgo_bean_constructions_total{bean=\"%s\"} %d\n", labelReplacer.Replace(b.Name), b.Constructed)
+		}
+		header("iocgo_bean_construction_failures_total", "counter", "Number of failed bean constructions.")
+		for _, b := range m.Beans {
+			_, _ = fmt.Fprintf(bw, "iocgo_bean_construction_failures_total{bean=\"%s\"} %d\n", labelReplacer.Replace(b.Name), b.Failed)
+		}
+		header("iocgo_bean_construction_duration_seconds", "histogram", "Duration of bean constructions in seconds.")
+		for _, b := range m.Beans {
+			histogram("iocgo_bean_construction_duration_seconds", fmt.Sprintf("bean=\"%s\"", labelReplacer.Replace(b.Name)), b.Duration)
+		}
+	}
+
+	if len(m.Methods) > 0 {
+		header("iocgo_proxy_call_duration_seconds", "histogram", "Duration of proxied method calls in seconds.")
+		for _, method := range m.Methods {
+			labels := fmt.Sprintf("interface=\"%s\",method=\"%s\"", labelReplacer.Replace(method.Interface), labelReplacer.Replace(method.Method))
+			histogram("iocgo_proxy_call_duration_seconds", labels, method.Duration)
+		}
+	}
+	return bw.Flush()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,206 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/do/v2"
+)
+
+// Module 可复用的 bean 组合，Install 中注册的 bean、别名与初始化器归属于该模块。
+// Imports 为依赖的模块，先于本模块安装；Exports 为对外公开的 bean 名称，模块外的 bean 只能依赖公开的 bean，
+// 在代码中直接获取不受限制
+type Module struct {
+	Name    string
+	Imports []*Module
+	Exports []string
+	Install func(*Container) error
+}
+
+// Install 按依赖顺序安装模块，同名模块在同一容器中只安装一次
+func (c *Container) Install(modules ...*Module) error {
+	for _, module := range modules {
+		if err := c.install(module, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OverrideModule 以 module 替换同名模块，须在安装前调用，用于测试中整体替换模块的实现
+func (c *Container) OverrideModule(module *Module) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.modules[module.Name]; ok {
+		panic("module '" + module.Name + "' already installed")
+	}
+	c.overrides[module.Name] = module
+}
+
+// Modules 获取已安装的模块名称，按安装顺序
+func (c *Container) Modules() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.installed)
+}
+
+func (c *Container) install(module *Module, chain []string) (err error) {
+	if slices.Contains(chain, module.Name) {
+		return fmt.Errorf("module import cycle: %s -> %s", strings.Join(chain, " -> "), module.Name)
+	}
+
+	c.mu.Lock()
+	_, ok := c.modules[module.Name]
+	if override, has := c.overrides[module.Name]; has {
+		module = override
+	}
+	c.mu.Unlock()
+	if ok {
+		return
+	}
+
+	chain = append(chain, module.Name)
+	for _, imported := range module.Imports {
+		if err = c.install(imported, chain); err != nil {
+			return
+		}
+	}
+
+	c.mu.Lock()
+	c.modules[module.Name] = module
+	c.installed = append(c.installed, module.Name)
+	c.mu.Unlock()
+
+	if module.Install != nil {
+		collided := c.within(module.Name, func() { err = module.Install(c) })
+		// Install 忽略了注册返回的错误时，同样报告与其它模块或容器中 bean 的名称冲突
+		if err = elseOf(err == nil, collided, err); err != nil {
+			// 安装失败的模块不视为已安装，可以再次安装
+			c.mu.Lock()
+			delete(c.modules, module.Name)
+			c.installed = slices.DeleteFunc(c.installed, func(name string) bool { return name == module.Name })
+			c.mu.Unlock()
+			return fmt.Errorf("install module '%s': %w", module.Name, err)
+		}
+	}
+	return
+}
+
+// 在模块中执行 register，期间注册的 bean 归属于该模块，返回期间发生的注册冲突
+func (c *Container) within(module string, register func()) error {
+	if module == "" {
+		register()
+		return nil
+	}
+
+	c.mu.Lock()
+	c.installing = append(c.installing, module)
+	start := len(c.collisions)
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.installing = c.installing[:len(c.installing)-1]
+		c.collisions = c.collisions[:start]
+		c.mu.Unlock()
+	}()
+	register()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return errors.Join(c.collisions[start:]...)
+}
+
+// bean 名称冲突的错误，指明双方所属的模块，安装模块期间的冲突由 Install 返回。调用方持有 mu
+func (c *Container) collide(name, module, location string) error {
+	var in string
+	if installing := c.installingModule(); installing != "" {
+		in = fmt.Sprintf(" in module '%s'", installing)
+	}
+	if module != "" {
+		in += fmt.Sprintf(", registered by module '%s'", module)
+	}
+	err := fmt.Errorf("%w: '%s'%s%s", ErrBeanExists, name, in, elseOf(location == "", "", "\n\tin "+location))
+	if c.installingModule() != "" {
+		c.collisions = append(c.collisions, err)
+	}
+	return err
+}
+
+// 校验模块公开的 bean 均已注册，条件注册之后执行
+func (c *Container) checkExports() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for _, name := range c.installed {
+		for _, export := range c.modules[name].Exports {
+			if b, ok := c.beans[c.resolve(export)]; !ok || b.typ == nil {
+				errs = append(errs, fmt.Errorf("module '%s' exports bean '%s' that is not registered", name, export))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 当前正在安装的模块
+func (c *Container) installingModule() string {
+	if l := len(c.installing); l > 0 {
+		return c.installing[l-1]
+	}
+	return ""
+}
+
+// bean 所属的模块，子作用域中沿用父容器注册的
+func (c *Container) moduleOf(name string) string {
+	for container := c; container != nil; container = container.parent {
+		container.mu.Lock()
+		b, ok := container.beans[name]
+		container.mu.Unlock()
+		if ok && b.module != "" {
+			return b.module
+		}
+	}
+	return ""
+}
+
+// 校验 requester 能否依赖 name：name 不属于模块、与 requester 属于同一模块或为所属模块公开的 bean。
+// 直接获取（requester 为空）不受限制
+func (c *Container) exported(requester, name string) error {
+	module := c.moduleOf(name)
+	if module == "" || requester == "" || c.moduleOf(requester) == module {
+		return nil
+	}
+
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.mu.Lock()
+	ok := root.exports(module, name)
+	root.mu.Unlock()
+	if !ok {
+		return errNotExported(name, module)
+	}
+	return nil
+}
+
+// 过滤当前正在创建的 bean 不能依赖的 bean，用于集合注入
+func (c *Container) visible(names []string) []string {
+	if !threadLocal.Ex(false) {
+		return names
+	}
+	requester := threadLocal.Load().requester()
+	return slices.DeleteFunc(names, func(name string) bool { return c.exported(requester, name) != nil })
+}
+
+// 模块是否公开名为 name 的 bean，调用方持有锁
+func (c *Container) exports(module, name string) bool {
+	m, ok := c.modules[module]
+	return ok && slices.ContainsFunc(m.Exports, func(export string) bool { return c.resolve(export) == name })
+}
+
+func errNotExported(name, module string) error {
+	return fmt.Errorf("%w: bean '%s' is not exported by module '%s'", do.ErrServiceNotFound, name, module)
+}
@@ -0,0 +1,207 @@
+package sdk
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Initialized 内置初始化器的名称，负责实例化非懒加载 bean 并执行 AddInitialized 注册的方法。
+// 其它初始化器可通过 Before/After 声明与其的先后关系
+const Initialized = "sdk.initialized"
+
+// 按 bean 名称声明的初始化先后关系
+type ordering struct {
+	dependsOn []string
+	before    []string
+	after     []string
+}
+
+type namedInitializer struct {
+	name string
+	Initializer
+}
+
+// bean 的初始化先后关系，不存在时创建。调用方持有 mu
+func (c *Container) ordering(name string) *ordering {
+	name = c.resolve(name)
+	o, ok := c.orders[name]
+	if !ok {
+		o = &ordering{}
+		c.orders[name] = o
+	}
+	return o
+}
+
+// DependsOn 声明 bean 依赖的其它 bean，被依赖的 bean 必须存在，且先于该 bean 实例化或初始化
+func (c *Container) DependsOn(name string, names ...string) {
+	c.mu.Lock()
+	o := c.ordering(name)
+	o.dependsOn = append(o.dependsOn, names...)
+	c.mu.Unlock()
+	for _, n := range names {
+		c.depends(c.resolve(name), n, nil, callerLocation(), false, false)
+	}
+}
+
+// Before 声明 bean 先于指定的 bean 实例化或初始化，指定的 bean 不存在时忽略
+func (c *Container) Before(name string, names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := c.ordering(name)
+	o.before = append(o.before, names...)
+}
+
+// After 声明 bean 晚于指定的 bean 实例化或初始化，指定的 bean 不存在时忽略
+func (c *Container) After(name string, names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := c.ordering(name)
+	o.after = append(o.after, names...)
+}
+
+// 获取所有初始化器，包括内置的 Initialized
+func (c *Container) initializers() (inits []namedInitializer, err error) {
+	for _, name := range c.assignable(typeOf[Initializer]()) {
+		i, iErr := invoke[Initializer](c, name, true)
+		if iErr != nil {
+			return nil, iErr
+		}
+		inits = append(inits, namedInitializer{name, i})
+	}
+
+	inits = append(inits, namedInitializer{Initialized, &singleInitializer{999, func(container *Container) (iErr error) {
+		if iErr = c.eagerInit(); iErr != nil {
+			return
+		}
+		for _, exec := range c.init {
+			if iErr = exec(); iErr != nil {
+				return iErr
+			}
+		}
+		return
+	}}})
+	return
+}
+
+// 按声明的先后关系对初始化器拓扑排序，Order 仅决定没有先后关系的初始化器之间的顺序
+func (c *Container) sortInitializers(inits []namedInitializer) (sorted []namedInitializer, err error) {
+	nodes := make(map[string]namedInitializer)
+	for _, i := range inits {
+		nodes[i.name] = i
+	}
+
+	edges := make(map[string][]string)
+	degree := make(map[string]int)
+	edge := func(from, to string) {
+		_, ok1 := nodes[from]
+		_, ok2 := nodes[to]
+		if !ok1 || !ok2 || from == to || slices.Contains(edges[from], to) {
+			return
+		}
+		edges[from] = append(edges[from], to)
+		degree[to]++
+	}
+
+	c.mu.Lock()
+	for _, i := range inits {
+		o, ok := c.orders[i.name]
+		if !ok {
+			continue
+		}
+		for _, n := range o.dependsOn {
+			n = c.resolve(n)
+			if b, exists := c.beans[n]; (!exists || b.typ == nil) && n != Initialized {
+				c.mu.Unlock()
+				return nil, fmt.Errorf("initializer '%s' depends on unknown bean '%s'", i.name, n)
+			}
+			edge(n, i.name)
+		}
+		for _, n := range o.after {
+			edge(c.resolve(n), i.name)
+		}
+		for _, n := range o.before {
+			edge(i.name, c.resolve(n))
+		}
+	}
+	c.mu.Unlock()
+
+	var ready []namedInitializer
+	for _, i := range inits {
+		if degree[i.name] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	for len(ready) > 0 {
+		slices.SortFunc(ready, func(a, b namedInitializer) int {
+			return elseOf(a.Order() == b.Order(), strings.Compare(a.name, b.name), elseOf(a.Order() > b.Order(), 1, -1))
+		})
+
+		i := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, i)
+		for _, n := range edges[i.name] {
+			if degree[n]--; degree[n] == 0 {
+				ready = append(ready, nodes[n])
+			}
+		}
+	}
+
+	if len(sorted) < len(inits) {
+		err = cycleOf(edges, degree)
+	}
+	return
+}
+
+// 实例化初始化器通过 DependsOn 声明的 bean
+func (c *Container) dependsOn(name string) (err error) {
+	c.mu.Lock()
+	var names []string
+	if o, ok := c.orders[name]; ok {
+		names = slices.Clone(o.dependsOn)
+	}
+	c.mu.Unlock()
+
+	for _, n := range names {
+		if n = c.resolve(n); n == Initialized {
+			continue
+		}
+		if _, err = invoke[any](c, n, true); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// 在拓扑排序未能处理的节点中查找一条循环依赖链，未处理的节点总存在未处理的前驱
+func cycleOf(edges map[string][]string, degree map[string]int) error {
+	var remaining []string
+	for n, d := range degree {
+		if d > 0 {
+			remaining = append(remaining, n)
+		}
+	}
+	slices.Sort(remaining)
+
+	predecessor := func(name string) string {
+		for _, from := range slices.Sorted(maps.Keys(edges)) {
+			if degree[from] > 0 && slices.Contains(edges[from], name) {
+				return from
+			}
+		}
+		return ""
+	}
+
+	var chain []string
+	name := remaining[0]
+	for !slices.Contains(chain, name) {
+		chain = append(chain, name)
+		name = predecessor(name)
+	}
+
+	chain = append(chain[slices.Index(chain, name):], name)
+	slices.Reverse(chain)
+	return &ErrCycle{Name: name, Chain: chain}
+}
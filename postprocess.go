@@ -0,0 +1,181 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/samber/do/v2"
+)
+
+// BeanPostProcessor bean 后置处理器，注册为 bean 后对其它 bean 的每个新实例生效，按 Order 与名称依次执行。
+// BeforeInit 在 init 方法之前、AfterInit 在其之后执行，返回值替换原实例，须可赋值给 bean 注册的类型。
+// 后置处理器自身及创建它时获取的依赖不会被处理
+type BeanPostProcessor interface {
+	BeforeInit(name string, typ reflect.Type, bean any) (any, error)
+	AfterInit(name string, typ reflect.Type, bean any) (any, error)
+}
+
+// bean 的装饰函数
+type decorator struct {
+	target   string
+	typ      reflect.Type
+	location string
+	apply    func(any) (any, error)
+}
+
+var processorType = typeOf[BeanPostProcessor]()
+
+// OnInit 注册 bean 的 init 方法，在后置处理器的 BeforeInit 与 AfterInit 之间执行
+func OnInit[T any](container *Container, name string, init func(T) error) {
+	container.mu.Lock()
+	defer container.mu.Unlock()
+	container.bean(container.resolve(name)).init = func(bean any) error {
+		t, ok := bean.(T)
+		if !ok {
+			return fmt.Errorf("%w: init method of bean '%s' required %s, but got %T", do.ErrServiceNotMatch, name, typeOf[T](), bean)
+		}
+		return init(t)
+	}
+}
+
+// Decorate 装饰名为 target 的 bean，在 AfterInit 之后按注册顺序执行，返回值替换原实例。
+// T 须与 bean 注册的类型一致，或为 bean 注册的接口类型
+func Decorate[T any](container *Container, target string, decorate func(T) (T, error)) {
+	DecorateAt(container, target, callerLocation(), decorate)
+}
+
+// DecorateAt 同 Decorate，location 为装饰函数的位置，用于错误信息，由生成代码调用
+func DecorateAt[T any](container *Container, target, location string, decorate func(T) (T, error)) {
+	container.mu.Lock()
+	defer container.mu.Unlock()
+	container.decorators = append(container.decorators, decorator{target, typeOf[T](), location, func(bean any) (any, error) {
+		t, ok := bean.(T)
+		if !ok {
+			return bean, fmt.Errorf("%w: decorator of bean '%s' required %s, but got %T", do.ErrServiceNotMatch, target, typeOf[T](), bean)
+		}
+		return decorate(t)
+	}})
+}
+
+// 包装 provider，在 bean 的上下文中创建实例，并对新实例依次执行 BeforeInit、init 方法、AfterInit 与装饰函数
+func provide[T any](container *Container, name string, provider func() (T, error)) func(do.Injector) (T, error) {
+	return func(do.Injector) (t T, err error) {
+		ctx, cancel := container.contextOf(name)
+		defer cancel()
+		// 上下文已取消时不再创建
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		if threadLocal.Ex(false) {
+			value := threadLocal.Load()
+			prev := value.swap(ctx)
+			defer value.swap(prev)
+		}
+		defer container.constructing(name)()
+
+		now := time.Now()
+		defer func() { container.metrics.observe(name, time.Since(now), err) }()
+		if t, err = provider(); err != nil {
+			if ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+				err = fmt.Errorf("%w: %w", ctx.Err(), err)
+			}
+			return
+		}
+		// 超时或取消后才返回的实例不再使用，执行创建期间注册的销毁方法并关闭
+		if err = ctx.Err(); err != nil {
+			err = errors.Join(fmt.Errorf("%w: bean created after its context was done", err), container.destroy(context.WithoutCancel(ctx), name), shutdown(t))
+			var zero T
+			t = zero
+			return
+		}
+		if t, err = postProcess(container, name, t); err == nil {
+			container.logger.Debug("bean created", "bean", name, "type", typeOf[T](), "duration", time.Since(now))
+		}
+		return
+	}
+}
+
+func postProcess[T any](c *Container, name string, t T) (T, error) {
+	typ := typeOf[T]()
+	processors, err := c.postProcessors(typ)
+	if err != nil {
+		return t, err
+	}
+
+	var obj any = t
+	for _, processor := range processors {
+		if obj, err = processor.BeforeInit(name, typ, obj); err != nil {
+			return t, err
+		}
+	}
+	if init := c.initOf(name); init != nil {
+		if err = init(obj); err != nil {
+			return t, err
+		}
+	}
+	for _, processor := range processors {
+		if obj, err = processor.AfterInit(name, typ, obj); err != nil {
+			return t, err
+		}
+	}
+	for _, d := range c.decoratorsOf(name) {
+		if obj, err = d.apply(obj); err != nil {
+			return t, err
+		}
+	}
+
+	re, ok := obj.(T)
+	if !ok {
+		return t, fmt.Errorf("%w: bean '%s' of type %s cannot be replaced by %T", do.ErrServiceNotMatch, name, typ, obj)
+	}
+	return re, nil
+}
+
+// 获取后置处理器，后置处理器自身及其依赖创建时返回空
+func (c *Container) postProcessors(typ reflect.Type) ([]BeanPostProcessor, error) {
+	if typ.Implements(processorType) {
+		return nil, nil
+	}
+
+	// do 直接调用 provider 时当前协程可能尚未进入容器，由 enter 负责清理
+	value, leave := enter()
+	defer leave()
+	if !value.process(true) {
+		return nil, nil
+	}
+	defer value.process(false)
+	return InvokeSlice[BeanPostProcessor](c)
+}
+
+// bean 的 init 方法，子作用域中沿用父容器注册的
+func (c *Container) initOf(name string) func(any) error {
+	for container := c; container != nil; container = container.parent {
+		container.mu.Lock()
+		b, ok := container.beans[name]
+		container.mu.Unlock()
+		if ok && b.init != nil {
+			return b.init
+		}
+	}
+	return nil
+}
+
+// bean 的装饰函数，包括所有祖先容器中注册的，祖先容器的先执行
+func (c *Container) decoratorsOf(name string) (decorators []decorator) {
+	for container := c; container != nil; container = container.parent {
+		container.mu.Lock()
+		var matched []decorator
+		for _, d := range container.decorators {
+			if container.resolve(d.target) == name {
+				matched = append(matched, d)
+			}
+		}
+		container.mu.Unlock()
+		decorators = append(matched, decorators...)
+	}
+	return
+}
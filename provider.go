@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"sync"
+)
+
+// Provider 每次调用 Get 时从容器获取 bean，transient bean 每次返回新的实例
+type Provider[T any] struct {
+	container *Container
+	name      string
+}
+
+// Lazy 首次调用 Get 时从容器获取 bean，之后返回同一实例。构造时不会获取依赖，可用于打破构造器之间的循环依赖
+type Lazy[T any] struct {
+	*lazy[T]
+}
+
+type lazy[T any] struct {
+	sync.Mutex
+	provider Provider[T]
+	resolved bool
+	value    T
+}
+
+// Optional 可选依赖，bean 未注册时为空而不是返回错误
+type Optional[T any] struct {
+	value   T
+	present bool
+}
+
+func NewProvider[T any](container *Container, name string) Provider[T] {
+	return Provider[T]{container, name}
+}
+
+func (p Provider[T]) Get() (T, error) {
+	return InvokeBean[T](p.container, p.name)
+}
+
+func NewLazy[T any](container *Container, name string) Lazy[T] {
+	return Lazy[T]{&lazy[T]{provider: NewProvider[T](container, name)}}
+}
+
+// Get 获取失败时不缓存错误，下次调用将重新获取
+func (l Lazy[T]) Get() (t T, err error) {
+	l.Lock()
+	defer l.Unlock()
+	if l.resolved {
+		return l.value, nil
+	}
+
+	if t, err = l.provider.Get(); err == nil {
+		l.value, l.resolved = t, true
+	}
+	return
+}
+
+// InvokeOptional 获取可选的 bean，仅在 bean 未注册时返回空的 Optional，bean 创建失败时仍返回错误
+func InvokeOptional[T any](container *Container, name string) (o Optional[T], err error) {
+	if !container.registered(container.resolve(name)) {
+		return
+	}
+
+	o.value, err = InvokeBean[T](container, name)
+	o.present = err == nil
+	return
+}
+
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.present
+}
+
+func (o Optional[T]) IsPresent() bool {
+	return o.present
+}
+
+func (o Optional[T]) OrElse(t T) T {
+	if o.present {
+		return o.value
+	}
+	return t
+}
+
+// 容器或父容器中是否注册了该 bean，包括直接通过 Inject() 注册到 do 的服务
+func (c *Container) registered(name string) bool {
+	for container := c; container != nil; container = container.parent {
+		if container.provides(name) {
+			return true
+		}
+	}
+	for container := c; container != nil; container = container.parent {
+		if container.provided(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package proxy
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Observer 代理方法调用的观察者，参数为接口、方法名与调用耗时
+type Observer func(iface, method string, elapsed time.Duration)
+
+var observer atomic.Pointer[Observer]
+
+// Observe 设置代理方法调用的观察者，nil 表示不观察
+func Observe(observe Observer) {
+	if observe == nil {
+		observer.Store(nil)
+		return
+	}
+	observer.Store(&observe)
+}
+
+// Invoked 由生成的代理方法在调用开始时执行，返回的方法在调用结束时通知观察者
+func Invoked(iface, method string) func() {
+	observe := observer.Load()
+	if observe == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() { (*observe)(iface, method, time.Since(start)) }
+}
@@ -10,6 +10,7 @@ import (
 
 var (
 	constructorMap = make(map[string][]func(any) (any, bool))
+	interfaces     = make(map[string]reflect.Type)
 )
 
 func Reg[T any](constructor func(T) (T, bool)) {
@@ -17,6 +18,7 @@ func Reg[T any](constructor func(T) (T, bool)) {
 	if !ok {
 		panic("this T type is not interface: " + n)
 	}
+	interfaces[n] = reflect.TypeOf((*T)(nil)).Elem()
 	constructorMap[n] = append(constructorMap[n], func(obj any) (any, bool) {
 		return constructor(obj.(T))
 	})
@@ -39,6 +41,14 @@ func New[T any](t T) (T, error) {
 	return zero, fmt.Errorf("no constructor found for %s", n)
 }
 
+// Interfaces 返回已注册代理的接口
+func Interfaces() (re []reflect.Type) {
+	for _, t := range interfaces {
+		re = append(re, t)
+	}
+	return
+}
+
 func generateInterfaceName[T any]() (name string, isInter bool) {
 	var t T
 
@@ -51,10 +61,15 @@ func generateInterfaceName[T any]() (name string, isInter bool) {
 		ox = ox.Elem()
 	}
 
+	return interfaceName(ox), ox.Kind() == reflect.Interface
+}
+
+// 类型的注册名称，以包路径限定
+func interfaceName(ox reflect.Type) (name string) {
 	name = ox.String()
-	idx := strings.LastIndexByte(name, '.')
-	name = ox.PkgPath() + name[idx:]
-	isInter = ox.Kind() == reflect.Interface
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		name = ox.PkgPath() + name[idx:]
+	}
 	return
 }
 
@@ -0,0 +1,48 @@
+package proxy
+
+import (
+	"reflect"
+	"sync"
+)
+
+var refreshers sync.Map
+
+// RegRefresh 注册接口 T 的间接实现，由生成代码调用。间接实现的每个方法都转发到 get 返回的当前实例，
+// 获取失败时最后一个返回值为 error 的方法返回该错误，其余方法 panic
+func RegRefresh[T any](constructor func(get func() (T, error)) T) {
+	n, ok := generateInterfaceName[T]()
+	if !ok {
+		panic("this T type is not interface: " + n)
+	}
+	refreshers.Store(n, func(get func() (any, error)) any {
+		return constructor(func() (t T, err error) {
+			obj, err := get()
+			if err != nil {
+				return
+			}
+			return obj.(T), nil
+		})
+	})
+}
+
+// Refresh 创建接口 T 的间接实现，未注册时返回 false
+func Refresh[T any](get func() (T, error)) (t T, ok bool) {
+	n, isInter := generateInterfaceName[T]()
+	if !isInter {
+		return
+	}
+	constructor, ok := refreshers.Load(n)
+	if !ok {
+		return
+	}
+	return constructor.(func(func() (any, error)) any)(func() (any, error) { return get() }).(T), true
+}
+
+// HasRefresh 接口 typ 是否注册了间接实现
+func HasRefresh(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Interface {
+		return false
+	}
+	_, ok := refreshers.Load(interfaceName(typ))
+	return ok
+}
@@ -0,0 +1,202 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/iocgo/sdk/proxy"
+	"github.com/samber/do/v2"
+)
+
+// ErrBeanHeld 被替换或刷新的 bean 的旧实例仍被不会重新创建的 bean 持有，替换与刷新仍然生效
+var ErrBeanHeld = errors.New("bean is held by a non-refreshable bean")
+
+// Refreshable 标记 bean 为 refresh 作用域：依赖的 bean 被 OverrideBean 替换或重新创建、读取的配置项变更时，
+// 关闭旧实例并重新创建。以接口类型获取时返回生成的间接实现，每次调用方法都转发到当前实例，
+// 依赖方无需重新创建即可使用新实例
+func (c *Container) Refreshable(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bean(c.resolve(name)).refresh = true
+}
+
+// Refresh 关闭并重新创建 names 及依赖它们的 refresh bean，未创建的 bean 在下次获取时创建
+func (c *Container) Refresh(names ...string) error {
+	resolved := make([]string, len(names))
+	for i, name := range names {
+		resolved[i] = c.resolve(name)
+	}
+	return c.refresh(resolved, nil)
+}
+
+// RefreshConfig 配置变更事件，重新创建读取了 keys 或其子项的 refresh bean，未指定 keys 时为所有读取配置的 refresh bean。
+// 须在配置源更新之后调用
+func (c *Container) RefreshConfig(keys ...string) error {
+	c.mu.Lock()
+	var names []string
+	for name, b := range c.beans {
+		if !b.refresh {
+			continue
+		}
+		read := slices.ContainsFunc(b.values, func(v value) bool { return changed(v.key, keys) })
+		if read || b.config != nil && changed(b.prefix, keys) {
+			names = append(names, name)
+		}
+	}
+	c.mu.Unlock()
+
+	slices.Sort(names)
+	return c.refresh(names, nil)
+}
+
+// 关闭 names 及传递依赖它们的 refresh bean，执行 override 后按原创建顺序重新创建。
+// 仅在锁内取出旧实例并重新注册，销毁方法、关闭与构造器均在锁外执行，其中可以获取 bean
+func (c *Container) refresh(names []string, override func()) error {
+	c.refreshing.Lock()
+	c.mu.Lock()
+	affected := c.affected(names)
+	// 持有旧实例的 bean 不影响替换与刷新，仅随结果一并返回
+	held := c.held(affected)
+
+	// 已创建且能重新注册的 bean，直接通过 do 注册的服务无法重新创建，保持不变
+	var rebuilt []string
+	for _, name := range c.order {
+		if !slices.Contains(affected, name) {
+			continue
+		}
+		if b, ok := c.beans[name]; ok && b.rebuild != nil || override != nil && slices.Contains(names, name) {
+			rebuilt = append(rebuilt, name)
+		}
+	}
+	c.order = slices.DeleteFunc(c.order, func(name string) bool { return slices.Contains(rebuilt, name) })
+	// 取出旧实例的销毁方法，新实例创建时注册的销毁方法不受影响
+	destroys := make([]func(context.Context) error, len(rebuilt))
+	for i, name := range rebuilt {
+		destroys[i] = c.destroys[name]
+		delete(c.destroys, name)
+	}
+	c.mu.Unlock()
+
+	// 重新注册后 do 不再持有旧实例，由容器关闭
+	instances := make([]any, len(rebuilt))
+	for i, name := range rebuilt {
+		instances[i], _ = do.InvokeNamed[any](c.inject, name)
+		if b, ok := c.meta(name); ok && b.rebuild != nil {
+			b.rebuild()
+		}
+	}
+	if override != nil {
+		override()
+	}
+	c.generation.Add(1)
+	c.refreshing.Unlock()
+
+	now := time.Now()
+	var errs []error
+	// 依赖方先于依赖关闭
+	for i, name := range slices.Backward(rebuilt) {
+		var err error
+		if destroys[i] != nil {
+			err = destroys[i](context.Background())
+		}
+		if err = errors.Join(err, shutdown(instances[i])); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown bean '%s': %w", name, err))
+		}
+	}
+	for _, name := range rebuilt {
+		if _, err := invoke[any](c, name, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if held != nil {
+		c.logger.Warn("refreshed beans still held", "error", held)
+	}
+	if len(rebuilt) > 0 {
+		c.logger.Info("beans refreshed", "beans", rebuilt, "duration", time.Since(now), "error", errors.Join(errs...))
+	}
+	return errors.Join(append(errs, held)...)
+}
+
+// 持有 affected 旧实例且不会重新创建的 bean，以注册了间接实现的接口类型依赖 refresh bean 的除外。调用方持有 mu
+func (c *Container) held(affected []string) error {
+	var errs []error
+	for _, name := range c.order {
+		b, ok := c.beans[name]
+		if !ok || slices.Contains(affected, name) {
+			continue
+		}
+		for _, dep := range c.dependencies(b) {
+			target := c.resolve(dep.name)
+			if dep.lazy || !slices.Contains(affected, target) || !slices.Contains(c.order, target) {
+				continue
+			}
+			if t, has := c.beans[target]; has && t.refresh && dep.typ != nil && proxy.HasRefresh(dep.typ) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%w: '%s' is held by '%s'", ErrBeanHeld, target, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// names 及传递依赖它们的 refresh bean，Provider/Lazy 延迟获取的依赖除外。调用方持有 mu
+func (c *Container) affected(names []string) []string {
+	affected := slices.Clone(names)
+	for grown := true; grown; {
+		grown = false
+		for name, b := range c.beans {
+			if !b.refresh || slices.Contains(affected, name) {
+				continue
+			}
+			if slices.ContainsFunc(c.dependencies(b), func(dep dependency) bool { return !dep.lazy && slices.Contains(affected, c.resolve(dep.name)) }) {
+				affected = append(affected, name)
+				grown = true
+			}
+		}
+	}
+	return affected
+}
+
+// refresh bean 的间接实现，未生成时返回实例本身。当前实例按 refresh 的次数缓存，获取失败的错误由间接实现返回
+func indirect[T any](container *Container, name string, t T) T {
+	type cached struct {
+		generation uint64
+		current    T
+	}
+	var cache atomic.Pointer[cached]
+	rf, ok := proxy.Refresh[T](func() (current T, err error) {
+		generation := container.generation.Load()
+		if c := cache.Load(); c != nil && c.generation == generation {
+			return c.current, nil
+		}
+
+		obj, err := invoke[any](container, name, true)
+		if err != nil {
+			return
+		}
+		current = obj.(T)
+		if px, pxErr := proxy.New[T](current); pxErr == nil {
+			current = px
+		}
+		cache.Store(&cached{generation, current})
+		return
+	})
+	return elseOf(ok, rf, t)
+}
+
+// 配置项 key 是否在变更的 keys 中，相同或互为前缀即视为变更
+func changed(key string, keys []string) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	key = strings.ToLower(key)
+	return slices.ContainsFunc(keys, func(k string) bool {
+		k = strings.ToLower(k)
+		return key == "" || key == k || strings.HasPrefix(key, k+".") || strings.HasPrefix(k, key+".")
+	})
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iocgo/sdk"
+)
+
+// Metrics 以 Prometheus 文本格式输出容器的 bean 构造与代理方法调用统计，如 engine.GET("/metrics", router.Metrics(container))
+func Metrics(container *sdk.Container) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		if err := container.Metrics().WritePrometheus(ctx.Writer); err != nil {
+			_ = ctx.Error(err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iocgo/sdk"
+)
+
+const (
+	// RequestScope 请求作用域名称，对应注解 Inject 的 scope="request"
+	RequestScope = "request"
+
+	scopeKey = "iocgo.scope"
+)
+
+var (
+	// ContextBean 请求作用域中 *gin.Context 的 bean 名称，与生成代码按类型推导的名称一致
+	ContextBean = sdk.NameOf[*gin.Context]()
+	// TraceIDBean 请求作用域中 TraceID 的 bean 名称
+	TraceIDBean = sdk.NameOf[TraceID]()
+
+	ErrNoScope = errors.New("request scope not found, the router.Scoped middleware is required")
+)
+
+// TraceID 请求的追踪 id
+type TraceID string
+
+// ScopeValue 将请求派生的值注册为请求作用域的 bean，名称重复时返回 sdk.ErrBeanExists
+type ScopeValue func(scope *sdk.Container, ctx *gin.Context) error
+
+// Scoped 为每个请求打开 request 作用域，注册 *gin.Context 与 values 派生的 bean，响应完成后关闭作用域
+func Scoped(container *sdk.Container, values ...ScopeValue) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scope := container.Scope(RequestScope)
+		defer func() {
+			if err := scope.Close(); err != nil {
+				_ = ctx.Error(err)
+			}
+		}()
+
+		err := sdk.ProvideBean[*gin.Context](scope, ContextBean, func() (*gin.Context, error) { return ctx, nil })
+		for _, value := range values {
+			if err != nil {
+				break
+			}
+			err = value(scope, ctx)
+		}
+		if err != nil {
+			_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		ctx.Set(scopeKey, scope)
+		ctx.Next()
+	}
+}
+
+// Value 注册名为 name 的请求作用域 bean，extract 在首次获取时执行
+func Value[T any](name string, extract func(*gin.Context) (T, error)) ScopeValue {
+	return func(scope *sdk.Container, ctx *gin.Context) error {
+		return sdk.ProvideBean[T](scope, name, func() (T, error) { return extract(ctx) })
+	}
+}
+
+// WithTraceID 从请求头 header 读取追踪 id 注册为 TraceID bean，不存在时生成并写入响应头
+func WithTraceID(header string) ScopeValue {
+	return func(scope *sdk.Container, ctx *gin.Context) error {
+		id := ctx.GetHeader(header)
+		if id == "" {
+			buf := make([]byte, 16)
+			_, _ = rand.Read(buf)
+			id = hex.EncodeToString(buf)
+			ctx.Header(header, id)
+		}
+		return sdk.ProvideBean[TraceID](scope, TraceIDBean, func() (TraceID, error) { return TraceID(id), nil })
+	}
+}
+
+// Scope 获取当前请求的作用域容器
+func Scope(ctx *gin.Context) (*sdk.Container, bool) {
+	value, ok := ctx.Get(scopeKey)
+	if !ok {
+		return nil, false
+	}
+	scope, ok := value.(*sdk.Container)
+	return scope, ok
+}
+
+// Invoke 从当前请求的作用域中获取 bean，不存在时回退到父容器
+func Invoke[T any](ctx *gin.Context, name string) (t T, err error) {
+	scope, ok := Scope(ctx)
+	if !ok {
+		err = ErrNoScope
+		return
+	}
+	return sdk.InvokeBean[T](scope, name)
+}
+
+// InvokeAs 从当前请求的作用域中按类型获取 bean
+func InvokeAs[T any](ctx *gin.Context) (t T, err error) {
+	scope, ok := Scope(ctx)
+	if !ok {
+		err = ErrNoScope
+		return
+	}
+	return sdk.InvokeAs[T](scope)
+}
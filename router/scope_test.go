@@ -0,0 +1,89 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iocgo/sdk"
+	"github.com/iocgo/sdk/router"
+)
+
+type session struct {
+	ctx     *gin.Context
+	traceID router.TraceID
+}
+
+func TestScoped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	container := sdk.NewContainer()
+
+	var closed int
+	sdk.ProvideScoped[*session](container, router.RequestScope, "session", func(scope *sdk.Container) (*session, error) {
+		ctx, err := sdk.InvokeBean[*gin.Context](scope, router.ContextBean)
+		if err != nil {
+			return nil, err
+		}
+		traceID, err := sdk.InvokeAs[router.TraceID](scope)
+		scope.OnDestroy("session", func(context.Context) error { closed++; return nil })
+		return &session{ctx, traceID}, err
+	})
+
+	engine := gin.New()
+	engine.Use(router.Scoped(container, router.WithTraceID("X-Trace-Id")))
+	engine.GET("/", func(ctx *gin.Context) {
+		s, err := router.Invoke[*session](ctx, "session")
+		require.NoError(t, err)
+		assert.Same(t, ctx, s.ctx)
+		ctx.String(http.StatusOK, string(s.traceID))
+	})
+
+	for i := 1; i <= 2; i++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Trace-Id", "trace")
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, "trace", w.Body.String())
+		assert.Equal(t, i, closed)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Len(t, w.Body.String(), 32)
+	assert.Equal(t, w.Body.String(), w.Header().Get("X-Trace-Id"))
+}
+
+func TestScopedGeneratedNames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	container := sdk.NewContainer()
+
+	// 与生成代码一致，按参数类型推导依赖的名称
+	sdk.ProvideScoped[*session](container, router.RequestScope, "*github.com/iocgo/sdk/router_test.session", func(scope *sdk.Container) (*session, error) {
+		ctx, err := sdk.InvokeBean[*gin.Context](scope, "*github.com/gin-gonic/gin.Context")
+		if err != nil {
+			return nil, err
+		}
+		traceID, err := sdk.InvokeBean[router.TraceID](scope, "github.com/iocgo/sdk/router.TraceID")
+		return &session{ctx, traceID}, err
+	})
+
+	engine := gin.New()
+	engine.Use(router.Scoped(container, router.WithTraceID("X-Trace-Id")))
+	engine.GET("/", func(ctx *gin.Context) {
+		s, err := router.Invoke[*session](ctx, "*github.com/iocgo/sdk/router_test.session")
+		require.NoError(t, err)
+		assert.Same(t, ctx, s.ctx)
+		ctx.String(http.StatusOK, string(s.traceID))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Trace-Id", "trace")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, "trace", w.Body.String())
+}
@@ -1,8 +1,10 @@
 package runtime
 
 import (
+	"bytes"
 	"fmt"
 	"runtime"
+	"strconv"
 	"sync"
 )
 
@@ -10,8 +12,29 @@ var (
 	littleBuf = sync.Pool{
 		New: func() any { bytes := make([]byte, 64); return &bytes },
 	}
+
+	goroutinePrefix = []byte("goroutine ")
+	createdBy       = []byte("created by ")
+	inRoutine       = []byte(" in goroutine ")
 )
 
+// Goroutine 协程的调用栈
+type Goroutine struct {
+	ID int64
+	// 创建该协程的协程，main 协程或无法解析时为 -1
+	Parent int64
+	// 创建该协程的函数
+	Creator string
+	// 由内向外的调用栈
+	Frames []Frame
+}
+
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
 func GetCurrentGoroutineID() (id int64) {
 	bp := littleBuf.Get().(*[]byte)
 	defer littleBuf.Put(bp)
@@ -24,3 +47,64 @@ func GetCurrentGoroutineID() (id int64) {
 	}
 	return
 }
+
+// Stacks 解析当前协程或所有协程的调用栈，按协程 id 索引。获取所有协程时会暂停程序，仅用于错误处理
+func Stacks(all bool) map[int64]*Goroutine {
+	buf := make([]byte, 8192)
+	for {
+		n := runtime.Stack(buf, all)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	goroutines := make(map[int64]*Goroutine)
+	for _, block := range bytes.Split(buf, []byte("\n\n")) {
+		lines := bytes.Split(bytes.TrimSpace(block), []byte("\n"))
+		if len(lines) == 0 || !bytes.HasPrefix(lines[0], goroutinePrefix) {
+			continue
+		}
+
+		g := &Goroutine{Parent: -1}
+		if _, err := fmt.Sscanf(string(lines[0]), "goroutine %d [", &g.ID); err != nil {
+			continue
+		}
+		// 函数与位置成对出现
+		for i := 1; i+1 < len(lines); i += 2 {
+			function, location := lines[i], bytes.TrimSpace(lines[i+1])
+			// ...additional frames elided...
+			if bytes.HasPrefix(function, []byte("...")) {
+				i--
+				continue
+			}
+			if bytes.HasPrefix(function, createdBy) {
+				function = function[len(createdBy):]
+				if idx := bytes.LastIndex(function, inRoutine); idx >= 0 {
+					if id, err := strconv.ParseInt(string(function[idx+len(inRoutine):]), 10, 64); err == nil {
+						g.Parent = id
+					}
+					function = function[:idx]
+				}
+				g.Creator = string(function)
+				break
+			}
+
+			frame := Frame{Function: string(function)}
+			if idx := bytes.LastIndexByte(function, '('); idx > 0 {
+				frame.Function = string(function[:idx])
+			}
+			if idx := bytes.LastIndex(location, []byte(" +0x")); idx > 0 {
+				location = location[:idx]
+			}
+			if idx := bytes.LastIndexByte(location, ':'); idx > 0 {
+				frame.File = string(location[:idx])
+				frame.Line, _ = strconv.Atoi(string(location[idx+1:]))
+			}
+			g.Frames = append(g.Frames, frame)
+		}
+		goroutines[g.ID] = g
+	}
+	return goroutines
+}
@@ -7,6 +7,9 @@ type ThreadLocal[T any] interface {
 	Store(T)
 	Ex(init bool) bool
 	Remove()
+
+	// Lookup 获取指定协程所存储的值
+	Lookup(id int64) (T, bool)
 }
 
 type goroutineLocal[T any] struct {
@@ -46,3 +49,11 @@ func (g goroutineLocal[T]) Ex(init bool) (ok bool) {
 func (g goroutineLocal[T]) Remove() {
 	g.m.Delete(GetCurrentGoroutineID())
 }
+
+func (g goroutineLocal[T]) Lookup(id int64) (t T, ok bool) {
+	value, ok := g.m.Load(id)
+	if ok {
+		t = value.(T)
+	}
+	return
+}
@@ -0,0 +1,66 @@
+package sdk
+
+// Scope 创建名为 name 的子作用域容器。获取 bean 时优先使用子作用域中的 bean，不存在时回退到父容器；
+// 通过 ProvideScoped 声明为该作用域的 bean 在每个子作用域中各自创建一次，并在子作用域 Close 时按创建的逆序关闭
+func (c *Container) Scope(name string) *Container {
+	s := newContainer(c.ctx)
+	s.parent, s.scope, s.timeout = c, name, c.timeout
+	s.logger, s.metrics = c.logger.With("scope", name), c.metrics
+
+	for _, provide := range c.scopedProviders(name) {
+		provide(s)
+	}
+	return s
+}
+
+// Close 关闭子作用域，等同于 Stop
+func (c *Container) Close() error {
+	return c.Stop()
+}
+
+// ScopeName 获取作用域名称，根容器为空
+func (c *Container) ScopeName() string {
+	return c.scope
+}
+
+// ProvideScoped 注册作用域 bean，在名为 scope 的子作用域中各自创建一次，provider 的参数为所在的子作用域容器。
+// 在根容器或其它作用域中获取该 bean 将返回错误，名称已注册时返回 ErrBeanExists
+func ProvideScoped[T any](container *Container, scope, name string, provider func(*Container) (T, error)) error {
+	if err := container.register(name, typeOf[T](), false, nil, nil); err != nil {
+		return err
+	}
+
+	container.mu.Lock()
+	defer container.mu.Unlock()
+	b := container.bean(name)
+	b.scope = scope
+	location, module := b.location, b.module
+	container.scoped[scope] = append(container.scoped[scope], func(s *Container) {
+		// 新建的子作用域中不存在同名 bean
+		_ = ProvideBean[T](s, name, func() (T, error) {
+			return provider(s)
+		})
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bean(name).location, s.bean(name).module = location, module
+	})
+	return nil
+}
+
+// 获取作用域 bean 的注册方法，包括所有祖先容器中声明的
+func (c *Container) scopedProviders(name string) (providers []func(*Container)) {
+	for container := c; container != nil; container = container.parent {
+		container.mu.Lock()
+		providers = append(container.scoped[name], providers...)
+		container.mu.Unlock()
+	}
+	return
+}
+
+// 容器自身是否注册了该 bean，子作用域中不存在时回退到父容器
+func (c *Container) provides(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.beans[name]
+	return ok && b.typ != nil && b.scope == ""
+}
@@ -0,0 +1,156 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/samber/do/v2"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+var (
+	ErrStopped = errors.New("container is stopped")
+
+	// 超过关闭期限时强制退出
+	exit = os.Exit
+)
+
+// ShutdownTimeout 设置 Stop 的关闭期限，Run 收到信号后超过该期限将强制退出进程
+func (c *Container) ShutdownTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// Hook 将 func()、func() error 或 func(context.Context) error 形式的方法统一为 func(context.Context) error，
+// 用于注解 Inject 的 init 与 destroy 方法。销毁方法收到的是关闭容器的上下文（含 StopWithContext 的期限）
+func Hook(method any) func(context.Context) error {
+	switch f := method.(type) {
+	case func():
+		return func(context.Context) error { f(); return nil }
+	case func() error:
+		return func(context.Context) error { return f() }
+	case func(context.Context):
+		return func(ctx context.Context) error { f(ctx); return nil }
+	case func(context.Context) error:
+		return f
+	default:
+		return func(context.Context) error { return fmt.Errorf("unsupported hook method: %T", method) }
+	}
+}
+
+// 关闭未交给 do 管理的实例，支持的接口与 do 关闭服务时一致
+func shutdown(obj any) error {
+	switch s := obj.(type) {
+	case do.ShutdownerWithContextAndError:
+		return s.Shutdown(context.Background())
+	case do.ShutdownerWithError:
+		return s.Shutdown()
+	case do.ShutdownerWithContext:
+		s.Shutdown(context.Background())
+	case do.Shutdowner:
+		s.Shutdown()
+	}
+	return nil
+}
+
+// OnDestroy 注册 bean 的销毁方法，在该 bean 被关闭时以关闭的上下文执行
+func (c *Container) OnDestroy(name string, destroy func(context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.destroys[c.resolve(name)] = destroy
+}
+
+// 执行并移除 bean 的销毁方法
+func (c *Container) destroy(ctx context.Context, name string) (err error) {
+	c.mu.Lock()
+	destroy, ok := c.destroys[name]
+	delete(c.destroys, name)
+	c.mu.Unlock()
+
+	if ok {
+		err = destroy(ctx)
+	}
+	return
+}
+
+// 记录单例 bean 的创建顺序，依赖总是先于依赖方创建完成
+func (c *Container) created(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, ok := c.beans[name]; ok && b.transient {
+		return
+	}
+	if !slices.Contains(c.order, name) {
+		c.order = append(c.order, name)
+	}
+}
+
+// Stop 停止接收新的 bean 请求，并按创建的逆序依次执行 bean 的关闭方法
+func (c *Container) Stop() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	return c.StopWithContext(ctx)
+}
+
+func (c *Container) StopWithContext(ctx context.Context) (err error) {
+	if !c.stopped.CompareAndSwap(false, true) {
+		return
+	}
+	c.cancel()
+
+	c.mu.Lock()
+	order := slices.Clone(c.order)
+	c.order = nil
+	c.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		c.logger.Log(context.Background(), c.lifecycleLevel(), "container stopped", "beans", len(order), "duration", time.Since(start), "error", err)
+	}()
+
+	slices.Reverse(order)
+	for i, name := range order {
+		if ctx.Err() != nil {
+			err = errors.Join(err, fmt.Errorf("shutdown deadline exceeded, %d beans remaining: %w", len(order)-i, ctx.Err()))
+			return
+		}
+
+		now := time.Now()
+		sErr := errors.Join(c.destroy(ctx, name), do.ShutdownNamedWithContext(ctx, c.inject, name))
+		if sErr != nil {
+			c.logger.Error("shutdown bean failed", "bean", name, "duration", time.Since(now), "error", sErr)
+			err = errors.Join(err, fmt.Errorf("shutdown bean '%s': %w", name, sErr))
+			continue
+		}
+		c.logger.Debug("shutdown bean", "bean", name, "duration", time.Since(now))
+	}
+
+	// 剩余未通过容器创建的服务
+	if sErr := c.inject.ShutdownWithContext(ctx); sErr != nil {
+		err = errors.Join(err, sErr)
+	}
+	return
+}
+
+// 收到信号后在期限内关闭容器，超时则强制退出
+func (c *Container) shutdown(sig os.Signal) (err error) {
+	c.logger.Info("received signal, shutting down", "signal", sig.String())
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- c.StopWithContext(ctx) }()
+
+	select {
+	case err = <-done:
+		return
+	case <-ctx.Done():
+		c.logger.Error("shutdown deadline exceeded, force exit", "timeout", c.timeout)
+		exit(1)
+		return ctx.Err()
+	}
+}
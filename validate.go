@@ -0,0 +1,224 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/samber/do/v2"
+)
+
+// Validate 校验所有已注册 bean 声明的依赖，一次性报告未注册的依赖、类型不匹配、循环依赖与无效的配置项。
+// 条件注册在元信息的副本上预演，校验结束后恢复，容器的状态不变。唯一的例外是配置源：尚未创建时为读取条件
+// 调用其 provider 临时创建一个不放入容器的实例，配置源依赖的 bean 随之创建；此外不会调用任何构造器。不应与注册或 Run 并发调用
+func (c *Container) Validate() error {
+	defer c.rehearse()()
+	cErr := c.applyConditionals()
+
+	c.mu.Lock()
+	var names []string
+	for name, b := range c.beans {
+		if b.typ != nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	errs := []error{cErr}
+	for _, name := range names {
+		for _, dep := range c.dependencies(c.beans[name]) {
+			if err := c.check(c.beans[name], dep); err != nil {
+				errs = append(errs, &ErrInvalidDependency{Bean: name, Dependency: dep.name, Location: c.beans[name].at(dep.location), Err: err})
+			}
+		}
+	}
+	// 装饰函数的参数与返回值须与 bean 注册的类型一致
+	for _, d := range c.decorators {
+		var err error
+		switch b, ok := c.beans[c.resolve(d.target)]; {
+		case !ok || b.typ == nil:
+			err = do.ErrServiceNotFound
+		case !b.typ.AssignableTo(d.typ) || !d.typ.AssignableTo(b.typ):
+			err = fmt.Errorf("%w: required %s, but provided %s", do.ErrServiceNotMatch, d.typ, b.typ)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("decorator of bean '%s': %w\n\tin %s", d.target, err, d.location))
+		}
+	}
+	errs = append(errs, c.cycles(names)...)
+	c.mu.Unlock()
+
+	if err := c.checkExports(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// 读取配置需要获取配置源 bean
+	return errors.Join(append(errs, c.checkValues())...)
+}
+
+// 校验依赖是否已注册、作用域是否可见且可赋值给声明的类型
+func (c *Container) check(requester *bean, dep dependency) error {
+	b, ok := c.beans[c.resolve(dep.name)]
+	switch {
+	case (!ok || b.typ == nil) && dep.optional:
+		return nil
+	case !ok || b.typ == nil:
+		return do.ErrServiceNotFound
+	case b.scope != "" && requester.scope == "":
+		return fmt.Errorf("%w: bean is scoped to '%s'", do.ErrServiceNotFound, b.scope)
+	case b.module != "" && b.module != requester.module && !c.exports(b.module, b.name):
+		return errNotExported(b.name, b.module)
+	case dep.typ == nil:
+		return nil
+	case !b.typ.AssignableTo(dep.typ):
+		return fmt.Errorf("%w: required %s, but provided %s", do.ErrServiceNotMatch, dep.typ, b.typ)
+	}
+	return nil
+}
+
+// Validate 预演条件注册期间替换的元信息，条件注册只修改副本且不注册到 do
+type preview struct {
+	beans        map[string]*bean
+	alias        map[string]string
+	orders       map[string]*ordering
+	scoped       map[string][]func(*Container)
+	conditionals []conditional
+	decorators   []decorator
+
+	once       sync.Once
+	properties Properties
+}
+
+// 以元信息的副本开始预演，返回结束预演并恢复原有元信息的函数
+func (c *Container) rehearse() func() {
+	c.mu.Lock()
+	c.aliasMu.Lock()
+	p := &preview{
+		beans:        c.beans,
+		alias:        c.alias,
+		orders:       c.orders,
+		scoped:       c.scoped,
+		conditionals: c.conditionals,
+		decorators:   c.decorators,
+	}
+	c.beans = make(map[string]*bean, len(p.beans))
+	for name, b := range p.beans {
+		clone := *b
+		clone.deps, clone.values = slices.Clone(b.deps), slices.Clone(b.values)
+		c.beans[name] = &clone
+	}
+	c.alias = maps.Clone(p.alias)
+	c.orders = make(map[string]*ordering, len(p.orders))
+	for name, o := range p.orders {
+		c.orders[name] = &ordering{slices.Clone(o.dependsOn), slices.Clone(o.before), slices.Clone(o.after)}
+	}
+	c.scoped = maps.Clone(p.scoped)
+	c.conditionals, c.decorators = slices.Clone(p.conditionals), slices.Clone(p.decorators)
+	clear(c.index)
+	c.preview.Store(p)
+	c.aliasMu.Unlock()
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		c.aliasMu.Lock()
+		defer c.mu.Unlock()
+		defer c.aliasMu.Unlock()
+		c.beans, c.alias, c.orders, c.scoped = p.beans, p.alias, p.orders, p.scoped
+		c.conditionals, c.decorators = p.conditionals, p.decorators
+		clear(c.index)
+		c.preview.Store(nil)
+	}
+}
+
+// 是否正在预演条件注册
+func (c *Container) previewing() bool {
+	return c.preview.Load() != nil
+}
+
+// 预演中的配置源：已创建时直接获取，否则调用未经后置处理的 provider 临时创建，不放入容器。
+// 这是预演中唯一会调用的构造器
+func (p *preview) source(c *Container) Properties {
+	p.once.Do(func() {
+		name, err := c.lookup(typeOf[Properties]())
+		if err != nil {
+			return
+		}
+		if slices.ContainsFunc(c.inject.ListInvokedServices(), func(service do.EdgeService) bool {
+			return service.ScopeID == c.inject.ID() && service.Service == name
+		}) {
+			p.properties, _ = invoke[Properties](c, name, true)
+			return
+		}
+		if b, ok := c.meta(name); ok && b.raw != nil {
+			if obj, rErr := b.raw(); rErr == nil {
+				p.properties, _ = obj.(Properties)
+			}
+		}
+	})
+	return p.properties
+}
+
+// 按声明的依赖查找所有循环依赖，Provider/Lazy 延迟获取的依赖不构成循环
+func (c *Container) cycles(names []string) (errs []error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var (
+		state            = make(map[string]int)
+		chain, locations []string
+		visit            func(name, location string)
+	)
+
+	visit = func(name, location string) {
+		chain, locations = append(chain, name), append(locations, location)
+		defer func() {
+			chain, locations = chain[:len(chain)-1], locations[:len(locations)-1]
+		}()
+
+		switch state[name] {
+		case visiting:
+			errs = append(errs, &ErrCycle{Name: name, Chain: slices.Clone(chain), Locations: slices.Clone(locations)})
+			return
+		case visited:
+			return
+		}
+
+		state[name] = visiting
+		if b, ok := c.beans[name]; ok {
+			for _, dep := range c.dependencies(b) {
+				if !dep.lazy {
+					visit(c.resolve(dep.name), b.at(dep.location))
+				}
+			}
+		}
+		state[name] = visited
+	}
+
+	for _, name := range names {
+		visit(name, "")
+	}
+	return
+}
+
+// Inspect 响应 `iocgo graph` 与 `iocgo validate`：按其设置的环境变量将依赖关系图或校验结果输出到 w，
+// 返回是否由这两个命令运行，是否退出由调用方决定
+func (c *Container) Inspect(w io.Writer) (inspected bool, err error) {
+	if format, ok := os.LookupEnv("IOCGO_GRAPH"); ok {
+		return true, c.WriteGraph(w, format)
+	}
+	if _, ok := os.LookupEnv("IOCGO_VALIDATE"); !ok {
+		return false, nil
+	}
+
+	if err = c.Validate(); err == nil {
+		_, err = fmt.Fprintln(w, "[iocgo] validate: ok")
+	}
+	return true, err
+}
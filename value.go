@@ -0,0 +1,168 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bean 构造器声明的配置项
+type value struct {
+	key      string
+	def      []string
+	typ      reflect.Type
+	location string
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Value 读取配置项 key 并转换为 T，支持 string、bool、整数、浮点数、time.Duration 及其切片。
+// 未配置时使用字符串形式的默认值 def，与配置值按相同规则转换
+func Value[T any](container *Container, key string, def ...string) (t T, err error) {
+	v, err := container.value(key, def, typeOf[T]())
+	if err != nil {
+		return
+	}
+	return v.Interface().(T), nil
+}
+
+// RequiresValue 声明 bean 的构造器读取的配置项，由生成代码调用，在 Run 与 Validate 时校验
+func RequiresValue[T any](container *Container, name, key string, def ...string) {
+	container.mu.Lock()
+	defer container.mu.Unlock()
+	b := container.bean(container.resolve(name))
+	b.values = append(b.values, value{key, def, typeOf[T](), callerLocation()})
+}
+
+func (c *Container) value(key string, def []string, typ reflect.Type) (v reflect.Value, err error) {
+	var raw any
+	if properties := c.properties(); properties != nil && properties.IsSet(key) {
+		raw = properties.Get(key)
+	} else if len(def) > 0 {
+		raw = def[0]
+	} else {
+		err = fmt.Errorf("config '%s' is not set", key)
+		return
+	}
+
+	if v, err = convert(raw, typ); err != nil {
+		err = fmt.Errorf("config '%s': %w", key, err)
+	}
+	return
+}
+
+// 校验所有 bean 声明的配置项是否存在且可转换，以及配置结构 bean 能否绑定
+func (c *Container) checkValues() error {
+	c.mu.Lock()
+	var values []value
+	var names []string
+	var configs []func() error
+	for _, name := range slices.Sorted(maps.Keys(c.beans)) {
+		b := c.beans[name]
+		for _, v := range b.values {
+			v.location = b.at(v.location)
+			values = append(values, v)
+			names = append(names, name)
+		}
+		if b.config != nil {
+			configs = append(configs, b.config)
+		}
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for _, config := range configs {
+		errs = append(errs, config())
+	}
+	for i, v := range values {
+		if _, err := c.value(v.key, v.def, v.typ); err != nil {
+			errs = append(errs, &ErrInvalidDependency{Bean: names[i], Dependency: v.key, Location: v.location, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func convert(raw any, typ reflect.Type) (v reflect.Value, err error) {
+	rv := reflect.ValueOf(raw)
+	if raw != nil && rv.Type().AssignableTo(typ) {
+		return rv, nil
+	}
+
+	v = reflect.New(typ).Elem()
+	if typ.Kind() == reflect.Slice {
+		var items []any
+		switch {
+		case raw == nil:
+		case rv.Kind() == reflect.Slice:
+			for i := range rv.Len() {
+				items = append(items, rv.Index(i).Interface())
+			}
+		default:
+			for _, item := range strings.Split(fmt.Sprint(raw), ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+
+		v.Set(reflect.MakeSlice(typ, 0, len(items)))
+		for i, item := range items {
+			elem, cErr := convert(item, typ.Elem())
+			if cErr != nil {
+				err = fmt.Errorf("[%d] %w", i, cErr)
+				return
+			}
+			v.Set(reflect.Append(v, elem))
+		}
+		return
+	}
+
+	s := strings.TrimSpace(fmt.Sprint(raw))
+	switch kind := typ.Kind(); {
+	case typ == durationType:
+		var d time.Duration
+		if n, nErr := strconv.ParseInt(s, 10, 64); nErr == nil {
+			d = time.Duration(n)
+		} else if d, err = time.ParseDuration(s); err != nil {
+			return
+		}
+		v.SetInt(int64(d))
+	case kind == reflect.String:
+		v.SetString(s)
+	case kind == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, typ.Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, typ.Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, typ.Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		err = fmt.Errorf("unsupported value type %s", typ)
+	}
+
+	if ne := (*strconv.NumError)(nil); errors.As(err, &ne) {
+		err = ne.Err
+	}
+	if err != nil {
+		err = fmt.Errorf("cannot convert %q to %s: %w", s, typ, err)
+	}
+	return
+}